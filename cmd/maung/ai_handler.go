@@ -8,8 +8,6 @@ import (
 	"net/http"
 	"os"
 	"strings"
-
-	"github.com/febrd/maungdb/engine/auth"
 )
 
 // ===========================
@@ -71,11 +69,12 @@ func handleAIChat(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Check if user is logged in
-	user, err := auth.CurrentUser()
+	sess, err := currentSession(r)
 	if err != nil {
 		sendAIError(w, "Anda harus login terlebih dahulu")
 		return
 	}
+	user := sess.User
 
 	// Decode request
 	var req AIChatRequest
@@ -79,11 +79,16 @@ func main() {
 		return
 
 	case "server":
-        port := "7070"
-        if len(os.Args) > 2 {
-            port = os.Args[2]
-        }
-        startServer(port)
+		port := "7070"
+		memory := false
+		for _, arg := range os.Args[2:] {
+			if arg == "--memory" {
+				memory = true
+			} else {
+				port = arg
+			}
+		}
+		startServer(port, memory)
 
 	default:
 		help()
@@ -267,13 +272,14 @@ func runQuery() {
 		return
 	}
 
-	result, err := executor.Execute(cmd)
+	result, err := executor.Execute(cmd, user)
 	if err != nil {
 		fmt.Println("❌", err)
 		return
 	}
 
 	printResult(result)
+	updateSessionDatabase(cmd)
 }
 
 func runQueryFromString(query string) {
@@ -294,22 +300,47 @@ func runQueryFromString(query string) {
 		return
 	}
 
-	result, err := executor.Execute(cmd)
+	result, err := executor.Execute(cmd, user)
 	if err != nil {
 		fmt.Println("❌", err)
 		return
 	}
 
 	printResult(result)
+	updateSessionDatabase(cmd)
+}
+
+// renamedDatabase: cmd ngaganti ngaran atawa miceun database. newDB ""
+// hartina dipiceun.
+func renamedDatabase(cmd *parser.Command) (db, newDB string, ok bool) {
+	if cmd.Explain {
+		return "", "", false
+	}
+	switch cmd.Type {
+	case parser.CmdDropDatabase:
+		return cmd.Database, "", true
+	case parser.CmdRenameDatabase:
+		return cmd.Database, cmd.NewName, true
+	}
+	return "", "", false
+}
+
+// updateSessionDatabase nuturkeun PICEUN / GANTI NGARAN DATABASE dina sesi CLI.
+func updateSessionDatabase(cmd *parser.Command) {
+	if db, newDB, ok := renamedDatabase(cmd); ok {
+		if err := auth.RenameSessionDatabase(db, newDB); err != nil {
+			fmt.Println("❌", err)
+		}
+	}
 }
 
 // Ganti fungsi printResult ku ieu:
 func printResult(result *executor.ExecutionResult) {
 	if result.Message != "" {
 		fmt.Println(result.Message)
-		return
 	}
 
+	// BALIKKEUN: pesen ditambah baris nu keuna
 	if len(result.Columns) == 0 {
 		return
 	}
@@ -421,6 +452,7 @@ func help() {
 	fmt.Println("\n🛠️  PARÉNTAH SISTEM (System Commands)")
 	fmt.Println("  maung init                       : Inisialisasi folder data (ngadamel kandang)")
 	fmt.Println("  maung server [port]              : Ngahurungkeun server (default port: 7070)")
+	fmt.Println("  maung server [port] --memory     : Server samentawis, data ngan dina RAM")
 	fmt.Println("  maung login <user> <pass>        : Masuk sateuacan ngakses database")
 	fmt.Println("  maung logout                     : Keluar tina sési")
 	fmt.Println("  maung whoami                     : Cek status login")
@@ -442,14 +474,31 @@ func help() {
 
 	fmt.Println("\n🧠  KAMUS MAUNGQL v2 (Query Syntax)")
 	fmt.Println("  TINGALI (SELECT)                 : TINGALI pegawai")
-	fmt.Println("  OMEAN (UPDATE)                   : OMEAN pegawai JADI gaji=9jt DIMANA id=1")
+	fmt.Println("  SALAKU (AS)                      : TINGALI nama, gaji*12 SALAKU taunan TI pegawai")
+	fmt.Println("  ITUNG/JUMLAH/RATA (COUNT/SUM/AVG): TINGALI ITUNG(*), JUMLAH(gaji) TI pegawai")
+	fmt.Println("  PANGLEUTIKNA/PANGGEDENA (MIN/MAX): TINGALI PANGGEDENA(tgl_masuk) TI pegawai")
+	fmt.Println("  KUMPULKEUN DUMASAR / ANU         : ... KUMPULKEUN DUMASAR divisi ANU ITUNG(*) > 5")
+	fmt.Println("  GABUNG [KENCA] ... DINA (JOIN)   : TINGALI * TI mhs GABUNG nilai DINA mhs.id = nilai.mhs_id")
+	fmt.Println("  SIMPEN ... NILAI (INSERT)        : SIMPEN pegawai (id,nama) NILAI (1,'Asep'), (2,'Siti')")
+	fmt.Println("  SIMPEN t TINGALI (INSERT SELECT) : SIMPEN arsip TINGALI * TI pegawai DIMANA aktif=false")
+	fmt.Println("  DAMEL t SALAKU TINGALI (CTAS)    : DAMEL rekap SALAKU TINGALI divisi, ITUNG(*) SALAKU n TI ...")
+	fmt.Println("  ATAWA OMEAN DUMASAR (UPSERT)     : SIMPEN stok (kode,jumlah) NILAI (A1,5) ATAWA OMEAN DUMASAR kode")
+	fmt.Println("  OMEAN (UPDATE)                   : OMEAN pegawai JADI gaji=gaji*2, divisi='IT' DIMANA id=1")
 	fmt.Println("  MICEUN (DELETE)                  : MICEUN TI pegawai DIMANA id=1")
+	fmt.Println("  BALIKKEUN (RETURNING)            : MICEUN TI pegawai DIMANA id=1 BALIKKEUN *")
 	fmt.Println("  DIMANA (WHERE)                   : ... DIMANA divisi=IT")
 	fmt.Println("  JIGA (LIKE/SEARCH)               : ... DIMANA nama JIGA 'sep'")
-	fmt.Println("  RUNTUYKEUN (ORDER BY)            : ... RUNTUYKEUN gaji TI_LUHUR")
+	fmt.Println("  DI / ANTARA (IN/BETWEEN)         : ... DIMANA divisi DI (IT,HR) SARENG umur ANTARA 20 JEUNG 30")
+	fmt.Println("  KOSONG / COCOG (IS NULL/REGEX)   : ... DIMANA email TEU KOSONG SARENG nama COCOG '^A'")
+	fmt.Println("  RUNTUYKEUN (ORDER BY)            : ... RUNTUYKEUN divisi NAEK, gaji TURUN")
 	fmt.Println("  SAKADAR (LIMIT)                  : ... SAKADAR 5")
 	fmt.Println("  LIWATAN (OFFSET)                 : ... LIWATAN 10")
 	fmt.Println("  SARENG / ATAWA (LOGIC)               : ... DIMANA umur>20 SARENG aktif=true")
+	fmt.Println("  SADAYANA (ALL)                   : MICEUN TI pegawai SADAYANA (tanpa DIMANA)")
+	fmt.Println("  MIMITIAN / ANGGEUSAN / BATALKEUN : Transaksi (BEGIN/COMMIT/ROLLBACK), dina maung cli")
+	fmt.Println("  PICEUN TABEL|DATABASE (DROP)     : PICEUN TABEL LAMUN AYA arsip")
+	fmt.Println("  KOSONGKEUN (TRUNCATE)            : KOSONGKEUN TABEL log")
+	fmt.Println("  GANTI NGARAN (RENAME)            : GANTI NGARAN TABEL pegawai JADI karyawan")
 
 	fmt.Println("\n💎  TIPE DATA (Data Types)")
 	fmt.Println("  INT, FLOAT                       : Angka (Bulat / Desimal)")
@@ -458,5 +507,6 @@ func help() {
 	fmt.Println("  DATE                             : Tanggal (YYYY-MM-DD)")
 	fmt.Println("  CHAR(n)                          : Karakter Panjang Tetap")
 	fmt.Println("  ENUM(a,b,c)                      : Pilihan Terbatas")
+	fmt.Println("  kolom:TIPE=nilai                 : Default, contona status:ENUM(A,B)=A")
 	fmt.Println("=======================================")
 }
\ No newline at end of file
@@ -2,14 +2,21 @@ package main
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
 	"github.com/febrd/maungdb/engine/auth"
 	"github.com/febrd/maungdb/engine/executor"
 	"github.com/febrd/maungdb/engine/parser"
 	"github.com/febrd/maungdb/engine/schema"
 	"github.com/febrd/maungdb/engine/storage"
+	"github.com/febrd/maungdb/internal/config"
 )
 
 // ===========================
@@ -38,11 +45,14 @@ type SchemaRequest struct {
 
 type QueryRequest struct {
 	Query string `json:"query"`
+	Tx    string `json:"tx,omitempty"` // handle transaksi ti MIMITIAN
 }
 
 type APIResponse struct {
 	Success bool                      `json:"success"`
 	Message string                    `json:"message,omitempty"`
+	Token   string                    `json:"token,omitempty"`
+	Tx      string                    `json:"tx,omitempty"`
 	Data    *executor.ExecutionResult `json:"data,omitempty"`
 	Error   string                    `json:"error,omitempty"`
 }
@@ -52,11 +62,21 @@ type CreateSchemaRequest struct {
 	Fields []string `json:"fields"` // PENTING: Tipe datana []string (Array)
 }
 
+// sessions nyimpen login unggal klien (browser / curl) misah-misah
+var sessions = auth.NewSessionStore(config.SessionTTL)
+
+// transactions nyimpen transaksi nu keur jalan dumasar handle-na
+var transactions = &txRegistry{entries: make(map[string]*txEntry)}
+
 // ===========================
 // Server Entry Point
 // ===========================
 
-func startServer(port string) {
+func startServer(port string, memory bool) {
+	if memory {
+		setupMemoryMode()
+	}
+
 	if err := storage.Init(); err != nil {
 		panic(err)
 	}
@@ -79,6 +99,9 @@ func startServer(port string) {
 	serveWebUI()
 
 	fmt.Println("🐯 MaungDB Server running")
+	if memory {
+		fmt.Println("🧠 Mode    : memory (data leungit basa server pareum)")
+	}
 	fmt.Println("🌐 Web UI  : http://localhost:" + port)
 	fmt.Println("🔌 API     : http://localhost:" + port + "/query")
 
@@ -87,12 +110,32 @@ func startServer(port string) {
 	}
 }
 
+// setupMemoryMode ngajalankeun server tanpa nyabak maung_data: user & schema
+// disimpen di diréktori samentawis, baris tabel di RAM (MemoryEngine).
+func setupMemoryMode() {
+	dir, err := os.MkdirTemp("", "maungdb-memory-")
+	if err != nil {
+		panic(err)
+	}
+	config.DataDir = dir
+	executor.SetEngine(storage.NewMemoryEngine())
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-sig
+		os.RemoveAll(dir)
+		os.Exit(0)
+	}()
+}
+
 // ===========================
 // Helpers
 // ===========================
 
 func setupHeader(w http.ResponseWriter) {
 	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 	w.Header().Set("Content-Type", "application/json")
 }
 
@@ -111,6 +154,27 @@ func sendSuccess(w http.ResponseWriter, msg string, data *executor.ExecutionResu
 	})
 }
 
+// sessionToken nyokot token tina header "Authorization: Bearer <token>"
+// atawa tina cookie session (Web UI).
+func sessionToken(r *http.Request) string {
+	if h := r.Header.Get("Authorization"); strings.HasPrefix(h, "Bearer ") {
+		return strings.TrimSpace(strings.TrimPrefix(h, "Bearer "))
+	}
+	if c, err := r.Cookie(config.SessionCookie); err == nil {
+		return c.Value
+	}
+	return ""
+}
+
+// currentSession mulangkeun session klien nu nyieun request.
+func currentSession(r *http.Request) (*auth.Session, error) {
+	token := sessionToken(r)
+	if token == "" {
+		return nil, errors.New("can login heula")
+	}
+	return sessions.Get(token)
+}
+
 // ===========================
 // AUTH HANDLERS
 // ===========================
@@ -128,17 +192,32 @@ func handleLogin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := auth.Login(req.Username, req.Password); err != nil {
+	user, err := auth.Authenticate(req.Username, req.Password)
+	if err != nil {
 		sendError(w, "Gagal Login: "+err.Error())
 		return
 	}
 
-	user, _ := auth.CurrentUser()
-	sendSuccess(
-		w,
-		fmt.Sprintf("✅ Login sukses salaku %s (%s)", user.Username, user.Role),
-		nil,
-	)
+	sess, err := sessions.Create(user)
+	if err != nil {
+		sendError(w, "Gagal nyieun session: "+err.Error())
+		return
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     config.SessionCookie,
+		Value:    sess.Token,
+		Path:     "/",
+		Expires:  sess.ExpiresAt,
+		HttpOnly: true,
+		SameSite: http.SameSiteLaxMode,
+	})
+
+	_ = json.NewEncoder(w).Encode(APIResponse{
+		Success: true,
+		Message: fmt.Sprintf("✅ Login sukses salaku %s (%s)", user.Username, user.Role),
+		Token:   sess.Token,
+	})
 }
 
 func handleLogout(w http.ResponseWriter, r *http.Request) {
@@ -148,22 +227,30 @@ func handleLogout(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := auth.Logout(); err != nil {
-		sendError(w, err.Error())
-		return
+	if token := sessionToken(r); token != "" {
+		sessions.Revoke(token)
+		transactions.rollbackSession(token)
 	}
 
+	http.SetCookie(w, &http.Cookie{
+		Name:   config.SessionCookie,
+		Value:  "",
+		Path:   "/",
+		MaxAge: -1,
+	})
+
 	sendSuccess(w, "✅ Logout hasil", nil)
 }
 
 func handleWhoami(w http.ResponseWriter, r *http.Request) {
 	setupHeader(w)
 
-	user, err := auth.CurrentUser()
+	sess, err := currentSession(r)
 	if err != nil {
 		sendError(w, err.Error())
 		return
 	}
+	user := sess.User
 
 	sendSuccess(
 		w,
@@ -190,7 +277,13 @@ func handleCreateDB(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := auth.RequireRole("supermaung"); err != nil {
+	sess, err := currentSession(r)
+	if err != nil {
+		sendError(w, err.Error())
+		return
+	}
+
+	if err := sess.User.HasRole("supermaung"); err != nil {
 		sendError(w, err.Error())
 		return
 	}
@@ -216,7 +309,8 @@ func handleUse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if _, err := auth.CurrentUser(); err != nil {
+	sess, err := currentSession(r)
+	if err != nil {
 		sendError(w, "❌ Anjeun kedah login heula")
 		return
 	}
@@ -227,7 +321,7 @@ func handleUse(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := auth.SetDatabase(req.Database); err != nil {
+	if err := sessions.SetDatabase(sess.Token, req.Database); err != nil {
 		sendError(w, err.Error())
 		return
 	}
@@ -248,12 +342,18 @@ func handleSchemaCreate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Cek Login & Role
-	if err := auth.RequireRole("admin"); err != nil {
+	sess, err := currentSession(r)
+	if err != nil {
+		sendError(w, "❌ Anjeun kedah login heula")
+		return
+	}
+	user := sess.User
+
+	if err := user.HasRole("admin"); err != nil {
 		sendError(w, "Akses ditolak: "+err.Error())
 		return
 	}
 
-	user, _ := auth.CurrentUser()
 	if user.Database == "" {
 		sendError(w, "Pilih database heula (use)")
 		return
@@ -300,18 +400,19 @@ func handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := auth.CurrentUser()
+	sess, err := currentSession(r)
 	if err != nil {
 		sendError(w, "❌ Anjeun kedah login heula")
 		return
 	}
+	user := &sess.User
 
 	if user.Database == "" {
 		sendError(w, "❌ Database can dipilih (POST /db/use)")
 		return
 	}
 
-	if err := auth.RequireRole("user"); err != nil {
+	if err := user.HasRole("user"); err != nil {
 		sendError(w, err.Error())
 		return
 	}
@@ -328,11 +429,117 @@ func handleQuery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	result, err := executor.Execute(cmd)
+	result, tx, err := executeQuery(sess.Token, req.Tx, cmd, user)
 	if err != nil {
 		sendError(w, "Execution Error: "+err.Error())
 		return
 	}
+	if db, newDB, ok := renamedDatabase(cmd); ok {
+		sessions.RenameDatabase(db, newDB)
+	}
+
+	_ = json.NewEncoder(w).Encode(APIResponse{
+		Success: true,
+		Message: "Query Berhasil",
+		Tx:      tx,
+		Data:    result,
+	})
+}
+
+// executeQuery ngajalankeun cmd. MIMITIAN mulangkeun handle transaksi anyar;
+// query nu mawa handle (field "tx") dijalankeun di jero transaksi éta nepi
+// ka ANGGEUSAN / BATALKEUN. Handle nu masih jalan dipulangkeun deui.
+func executeQuery(token, handle string, cmd *parser.Command, user *auth.User) (*executor.ExecutionResult, string, error) {
+	if handle == "" {
+		if cmd.Type != parser.CmdBegin {
+			result, err := executor.Execute(cmd, user)
+			return result, "", err
+		}
+
+		tx, err := executor.Begin()
+		if err != nil {
+			return nil, "", err
+		}
+		transactions.add(tx, token)
+		return &executor.ExecutionResult{
+			Message: "✅ Transaksi dimimitian (kirim handle tx dina unggal query, tungtungan ku ANGGEUSAN atawa BATALKEUN)",
+		}, tx.ID, nil
+	}
+
+	tx, err := transactions.get(handle, token)
+	if err != nil {
+		return nil, "", err
+	}
+
+	result, err := tx.Execute(cmd, user)
+	if tx.Done() {
+		transactions.remove(handle)
+		return result, "", err
+	}
+	return result, handle, err
+}
+
+// ===========================
+// TRANSACTION REGISTRY
+// ===========================
+
+type txEntry struct {
+	tx    *executor.Transaction
+	token string // session nu boga transaksi
+}
+
+type txRegistry struct {
+	mu      sync.Mutex
+	entries map[string]*txEntry
+}
+
+func (r *txRegistry) add(tx *executor.Transaction, token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.sweep()
+	r.entries[tx.ID] = &txEntry{tx: tx, token: token}
+}
+
+// get mulangkeun transaksi; handle session séjén dianggap teu aya.
+func (r *txRegistry) get(handle, token string) (*executor.Transaction, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.entries[handle]
+	if !ok || e.token != token {
+		return nil, errors.New("transaksi teu kapanggih (geus réngsé atawa lain milik session ieu)")
+	}
+	return e.tx, nil
+}
+
+func (r *txRegistry) remove(handle string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.entries, handle)
+}
+
+// rollbackSession ngabatalkeun sakabéh transaksi milik session (logout).
+func (r *txRegistry) rollbackSession(token string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for handle, e := range r.entries {
+		if e.token == token {
+			e.tx.Rollback()
+			delete(r.entries, handle)
+		}
+	}
+}
 
-	sendSuccess(w, "Query Berhasil", result)
+// sweep ngabatalkeun transaksi nu session-na geus kadaluwarsa. Make
+// Alive (lain Get) supaya sweep teu ngaperpanjang session.
+func (r *txRegistry) sweep() {
+	for handle, e := range r.entries {
+		if !sessions.Alive(e.token) {
+			e.tx.Rollback()
+			delete(r.entries, handle)
+		}
+	}
 }
@@ -0,0 +1,44 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/executor"
+)
+
+func TestSweepRollsBackExpiredSessionTx(t *testing.T) {
+	saved := sessions
+	defer func() { sessions = saved }()
+	sessions = auth.NewSessionStore(100 * time.Millisecond)
+
+	sess, err := sessions.Create(&auth.User{Username: "ujang", Role: "user"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tx, err := executor.Begin()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &txRegistry{entries: make(map[string]*txEntry)}
+	r.add(tx, sess.Token)
+
+	// sweep saméméh kadaluwarsa teu meunang ngaperpanjang session
+	time.Sleep(60 * time.Millisecond)
+	r.sweep()
+	if tx.Done() {
+		t.Fatal("transaksi dibatalkeun saméméh session kadaluwarsa")
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	r.sweep()
+
+	if !tx.Done() {
+		t.Fatal("transaksi session nu kadaluwarsa can dibatalkeun")
+	}
+	if _, err := r.get(tx.ID, sess.Token); err == nil {
+		t.Fatal("transaksi masih kadaptar sanggeus sweep")
+	}
+}
@@ -25,12 +25,16 @@ func startShell() {
 		if user != nil && user.Database != "" {
 			prompt = fmt.Sprintf("maung[%s]> ", user.Database)
 		}
+		if shellTx != nil {
+			prompt = strings.TrimSuffix(prompt, "> ") + "*> "
+		}
 
 		fmt.Print(prompt)
 
 		line, err := reader.ReadString('\n')
 		if err != nil {
 			fmt.Println()
+			rollbackShellTx()
 			return
 		}
 
@@ -49,6 +53,7 @@ func startShell() {
 		switch cmdName {
 
 		case "exit", "quit":
+			rollbackShellTx()
 			return
 
 		case "help":
@@ -79,6 +84,7 @@ func startShell() {
 			continue
 
 		case "logout":
+			rollbackShellTx()
 			if err := auth.Logout(); err != nil {
 				fmt.Println("❌", err)
 				continue
@@ -95,7 +101,7 @@ func startShell() {
 			if len(os.Args) > 2 {
 				port = os.Args[2]
 			}
-			startServer(port)
+			startServer(port, false)
 			continue
 			
 		case "createuser":
@@ -264,26 +270,72 @@ func startShell() {
 // "text/tabwriter"
 
 func processQuery(line string) {
+	user, err := auth.CurrentUser()
+	if err != nil {
+		fmt.Println("❌", err)
+		return
+	}
+
 	cmd, err := parser.Parse(line)
 	if err != nil {
 		fmt.Println("❌", err)
 		return
 	}
 
-	result, err := executor.Execute(cmd)
+	result, err := executeInShell(cmd, user)
 	if err != nil {
 		fmt.Println("❌", err)
 		return
 	}
 
 	renderTable(result)
+	updateSessionDatabase(cmd)
+}
+
+// shellTx nyaeta transaksi nu keur jalan dina shell ieu (MIMITIAN).
+var shellTx *executor.Transaction
+
+func executeInShell(cmd *parser.Command, user *auth.User) (*executor.ExecutionResult, error) {
+	if shellTx != nil {
+		result, err := shellTx.Execute(cmd, user)
+		if shellTx.Done() {
+			shellTx = nil
+		}
+		return result, err
+	}
+
+	if cmd.Type != parser.CmdBegin {
+		return executor.Execute(cmd, user)
+	}
+
+	tx, err := executor.Begin()
+	if err != nil {
+		return nil, err
+	}
+	shellTx = tx
+	return &executor.ExecutionResult{
+		Message: "✅ Transaksi dimimitian (ANGGEUSAN pikeun nyimpen, BATALKEUN pikeun ngabatalkeun)",
+	}, nil
+}
+
+// rollbackShellTx ngabatalkeun transaksi nu can anggeus (exit / logout).
+func rollbackShellTx() {
+	if shellTx == nil {
+		return
+	}
+	shellTx.Rollback()
+	shellTx = nil
+	fmt.Println("⚠️  Transaksi nu can anggeus dibatalkeun")
 }
 
 // renderTable nyieun tampilan tabel siga MySQL
 func renderTable(result *executor.ExecutionResult) {
 	if result.Message != "" {
 		fmt.Println(result.Message)
-		return
+		// BALIKKEUN: pesen ditambah baris nu keuna
+		if len(result.Columns) == 0 {
+			return
+		}
 	}
 
 	if len(result.Columns) == 0 {
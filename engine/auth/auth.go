@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -35,9 +36,19 @@ func sessionFilePath() string {
 }
 
 func Login(username, password string) error {
+	user, err := Authenticate(username, password)
+	if err != nil {
+		return err
+	}
+	return writeSession(user)
+}
+
+// Authenticate mariksa username & password tina users.maung tanpa nulis
+// session file. Dipake ku server pikeun nyieun session per klien.
+func Authenticate(username, password string) (*User, error) {
 	file, err := os.Open(userFilePath())
 	if err != nil {
-		return errors.New("system user file teu kapanggih")
+		return nil, errors.New("system user file teu kapanggih")
 	}
 	defer file.Close()
 
@@ -53,12 +64,12 @@ func Login(username, password string) error {
 		if user.Username == username &&
 			bcrypt.CompareHashAndPassword([]byte(hash), []byte(password)) == nil {
 
-			user.Database = "" 
-			return writeSession(user)
+			user.Database = ""
+			return user, nil
 		}
 	}
 
-	return errors.New("login gagal")
+	return nil, errors.New("login gagal")
 }
 
 func Logout() error {
@@ -108,35 +119,59 @@ func SetDatabase(db string) error {
 		return err
 	}
 
-	if u.Role != "supermaung" {
-		allowed := false
-		for _, d := range u.Databases {
-			if d == db {
-				allowed = true
-				break
-			}
-		}
-		if !allowed {
-			return errors.New("teu boga aksés ka database ieu")
-		}
+	if err := u.CanUseDatabase(db); err != nil {
+		return err
 	}
 
 	u.Database = db
 	return writeSession(u)
 }
 
-func RequireRole(minRole string) error {
+// RenameSessionDatabase ngaganti ngaran database (newDB "" = dipiceun)
+// dina sesi CLI, sanggeus GANTI NGARAN / PICEUN DATABASE.
+func RenameSessionDatabase(db, newDB string) error {
 	u, err := CurrentUser()
 	if err != nil {
-		return err
+		return nil // can login: euweuh sesi nu kudu dirobah
 	}
 
+	u.Databases = renameInList(u.Databases, db, newDB)
+	if u.Database == db {
+		u.Database = newDB
+	}
+	return writeSession(u)
+}
+
+// CanUseDatabase mariksa naha user meunang make database db.
+func (u *User) CanUseDatabase(db string) error {
+	if u.Role == "supermaung" {
+		return nil
+	}
+	for _, d := range u.Databases {
+		if d == db {
+			return nil
+		}
+	}
+	return errors.New("teu boga aksés ka database ieu")
+}
+
+// HasRole mariksa naha role user sahenteuna minRole.
+func (u *User) HasRole(minRole string) error {
 	if config.Roles[u.Role] > config.Roles[minRole] {
 		return errors.New("hak aksés teu cukup")
 	}
 	return nil
 }
 
+func RequireRole(minRole string) error {
+	u, err := CurrentUser()
+	if err != nil {
+		return err
+	}
+
+	return u.HasRole(minRole)
+}
+
 func RequireDatabase() error {
 	u, err := CurrentUser()
 	if err != nil {
@@ -235,6 +270,15 @@ func updateUser(username string, fn func(*User)) error {
 }
 
 func updateUserRaw(username string, fn func([]string)) error {
+	return updateUsersRaw(func(parts []string) {
+		if parts[0] == username {
+			fn(parts)
+		}
+	})
+}
+
+// updateUsersRaw nulis ulang users.maung; fn dipanggil pikeun unggal baris.
+func updateUsersRaw(fn func([]string)) error {
 	file, err := os.Open(userFilePath())
 	if err != nil {
 		return err
@@ -245,15 +289,16 @@ func updateUserRaw(username string, fn func([]string)) error {
 	sc := bufio.NewScanner(file)
 	for sc.Scan() {
 		parts := strings.Split(sc.Text(), "|")
-		if parts[0] == username {
-			fn(parts)
-			lines = append(lines, strings.Join(parts, "|"))
-		} else {
-			lines = append(lines, sc.Text())
-		}
+		fn(parts)
+		lines = append(lines, strings.Join(parts, "|"))
+	}
+
+	if err := sc.Err(); err != nil {
+		return err
 	}
+	file.Close()
 
-	return os.WriteFile(
+	return fsutil.WriteFile(
 		userFilePath(),
 		[]byte(strings.Join(lines, "\n")+"\n"),
 		0644,
@@ -8,6 +8,7 @@ import (
 	"strings"
 
 	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
 )
 
 func CanAccessDB(username, db string) bool {
@@ -43,6 +44,83 @@ func Grant(username, role, db string) error {
 	return err
 }
 
+// RevokeDatabase miceun grant jeung aksés user ka database db (basa
+// database dipiceun).
+func RevokeDatabase(db string) error {
+	return renameDatabaseAccess(db, "")
+}
+
+// RenameDatabase ngaganti ngaran database dina grant jeung aksés user.
+func RenameDatabase(db, newDB string) error {
+	return renameDatabaseAccess(db, newDB)
+}
+
+// renameDatabaseAccess: newDB == "" hartina aksés ka db dipiceun.
+func renameDatabaseAccess(db, newDB string) error {
+	if err := rewriteGrants(db, newDB); err != nil {
+		return err
+	}
+
+	err := updateUsersRaw(func(parts []string) {
+		if len(parts) < 4 {
+			return
+		}
+		if parts[3] != "" && parts[3] != "*" {
+			parts[3] = strings.Join(renameInList(strings.Split(parts[3], ","), db, newDB), ",")
+		}
+	})
+	if err != nil && newDB != "" {
+		// grant dibalikkeun supaya teu satengah diganti
+		_ = rewriteGrants(newDB, db)
+	}
+	return err
+}
+
+func rewriteGrants(db, newDB string) error {
+	path := filepath.Join(config.DataDir, config.SystemDir, config.GrantsFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSuffix(string(data), "\n"), "\n") {
+		p := strings.Split(line, "|")
+		if len(p) == 3 && p[2] == db {
+			if newDB == "" {
+				continue
+			}
+			p[2] = newDB
+			line = strings.Join(p, "|")
+		}
+		lines = append(lines, line)
+	}
+
+	content := strings.Join(lines, "\n")
+	if content != "" {
+		content += "\n"
+	}
+	return fsutil.WriteFile(path, []byte(content), 0644)
+}
+
+// renameInList ngaganti (atawa miceun) db dina daptar database user.
+func renameInList(list []string, db, newDB string) []string {
+	var out []string
+	for _, d := range list {
+		if d == db {
+			if newDB == "" {
+				continue
+			}
+			d = newDB
+		}
+		out = append(out, d)
+	}
+	return out
+}
+
 func RequireDBAccess() error {
 	u, err := CurrentUser()
 	if err != nil {
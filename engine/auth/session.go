@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"sync"
+	"time"
+)
+
+// Session nyaeta login hiji klien server. Unggal browser / klien curl boga
+// token sorangan, jadi user & database nu dipilih teu pacampur deui.
+type Session struct {
+	Token     string
+	User      User
+	ExpiresAt time.Time
+}
+
+// SessionStore nyimpen session dina memori server.
+type SessionStore struct {
+	mu       sync.Mutex
+	ttl      time.Duration
+	sessions map[string]*Session
+}
+
+func NewSessionStore(ttl time.Duration) *SessionStore {
+	return &SessionStore{
+		ttl:      ttl,
+		sessions: make(map[string]*Session),
+	}
+}
+
+// Create ngadamel session anyar pikeun user nu geus lulus Authenticate.
+func (s *SessionStore) Create(u *User) (*Session, error) {
+	token, err := newToken()
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.sweep()
+
+	sess := &Session{
+		Token:     token,
+		User:      *u,
+		ExpiresAt: time.Now().Add(s.ttl),
+	}
+	s.sessions[token] = sess
+
+	copied := *sess
+	return &copied, nil
+}
+
+// Get mulangkeun salinan session. Session nu masih dipake diperpanjang.
+func (s *SessionStore) Get(token string) (*Session, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[token]
+	if !ok || token == "" {
+		return nil, errors.New("can login heula")
+	}
+
+	if time.Now().After(sess.ExpiresAt) {
+		delete(s.sessions, token)
+		return nil, errors.New("session kadaluwarsa, login deui")
+	}
+
+	sess.ExpiresAt = time.Now().Add(s.ttl)
+
+	copied := *sess
+	return &copied, nil
+}
+
+// Alive: session masih aya sarta can kadaluwarsa. Béda jeung Get, waktu
+// kadaluwarsana teu diperpanjang (dipaké ku nu mariksa di tukang).
+func (s *SessionStore) Alive(token string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[token]
+	return ok && !time.Now().After(sess.ExpiresAt)
+}
+
+// SetDatabase milih database pikeun hiji session.
+func (s *SessionStore) SetDatabase(token, db string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	sess, ok := s.sessions[token]
+	if !ok {
+		return errors.New("can login heula")
+	}
+
+	if err := sess.User.CanUseDatabase(db); err != nil {
+		return err
+	}
+
+	sess.User.Database = db
+	return nil
+}
+
+// RenameDatabase ngaganti ngaran database (newDB "" = dipiceun) dina
+// sakabéh session, sanggeus GANTI NGARAN / PICEUN DATABASE.
+func (s *SessionStore) RenameDatabase(db, newDB string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, sess := range s.sessions {
+		sess.User.Databases = renameInList(sess.User.Databases, db, newDB)
+		if sess.User.Database == db {
+			sess.User.Database = newDB
+		}
+	}
+}
+
+// Revoke ngahapus session (logout).
+func (s *SessionStore) Revoke(token string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, token)
+}
+
+// sweep miceun session nu geus kadaluwarsa. Kudu dipanggil bari nyekel mu.
+func (s *SessionStore) sweep() {
+	now := time.Now()
+	for token, sess := range s.sessions {
+		if now.After(sess.ExpiresAt) {
+			delete(s.sessions, token)
+		}
+	}
+}
+
+func newToken() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,545 @@
+package executor
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// AGRÉGAT & KUMPULKEUN DUMASAR
+// =======================
+//
+// TINGALI nu ngandung ITUNG / JUMLAH / RATA / PANGLEUTIKNA / PANGGEDENA,
+// atawa KUMPULKEUN DUMASAR, ngahasilkeun hiji baris per grup. Unggal fungsi
+// agrégat dijadikeun kolom "virtual" (ngaranna sarua jeung fungsina, contona
+// "JUMLAH(gaji)") dina baris grup, jadi daptar kolom jeung ANU bisa
+// dievaluasi ku compileScalar / compileWhere biasa:
+//
+//	TINGALI divisi, JUMLAH(gaji) / ITUNG(*) TI pegawai KUMPULKEUN DUMASAR divisi
+//	-> baris grup: [<kolom tabel>..., JUMLAH(gaji), ITUNG(*)]
+//
+// Kolom tabel dina baris grup dicokot ti baris mimiti grup, jadi ngan kolom
+// KUMPULKEUN DUMASAR nu meunang dipaké di luar fungsi agrégat.
+
+// aggregatePlan nyaéta TINGALI agrégat nu geus dibeungkeut ka kolom.
+type aggregatePlan struct {
+	funcs []aggFunc
+	cols  []schema.Column // kolom tabel + kolom virtual hasil agrégat
+	proj  *projection     // dievaluasi kana baris grup
+
+	groupBy []int // posisi kolom KUMPULKEUN DUMASAR
+	having  predicate
+	seen    map[string]bool
+}
+
+// aggFunc nyaéta hiji fungsi agrégat dina query.
+type aggFunc struct {
+	name string // ITUNG, JUMLAH, ...
+	arg  *scalar
+	typ  string // tipe hasil
+}
+
+// compileAggregate mulangkeun nil lamun TINGALI lain query agrégat (euweuh
+// fungsi agrégat, KUMPULKEUN DUMASAR, atawa ANU).
+func compileAggregate(cmd *parser.Command, cols []schema.Column) (*aggregatePlan, error) {
+	if !hasAggregate(cmd.Fields) && cmd.GroupBy == nil && cmd.Having == nil {
+		return nil, nil
+	}
+
+	a := &aggregatePlan{
+		cols: append([]schema.Column(nil), cols...),
+		seen: make(map[string]bool),
+	}
+
+	grouped := make(map[int]bool)
+	for _, name := range cmd.GroupBy {
+		pos, err := lookupColumn(name, cols)
+		if err != nil {
+			return nil, err
+		}
+		if pos < 0 {
+			return nil, fmt.Errorf("kolom '%s' teu kapanggih", name)
+		}
+		a.groupBy = append(a.groupBy, pos)
+		grouped[pos] = true
+	}
+
+	// tanpa daptar kolom: kolom-kolom KUMPULKEUN DUMASAR
+	fields := cmd.Fields
+	if fields == nil {
+		if cmd.GroupBy == nil {
+			return nil, fmt.Errorf("ANU butuh KUMPULKEUN DUMASAR atawa fungsi agrégat dina daptar kolom")
+		}
+		for _, name := range cmd.GroupBy {
+			fields = append(fields, parser.SelectItem{Expr: &parser.Ident{Name: name}})
+		}
+	}
+
+	items := make([]parser.SelectItem, len(fields))
+	for i, f := range fields {
+		if f.Star {
+			return nil, fmt.Errorf("* teu bisa dipaké bareng fungsi agrégat atawa KUMPULKEUN DUMASAR")
+		}
+		expr, err := a.bind(f.Expr, cols, grouped)
+		if err != nil {
+			return nil, err
+		}
+		items[i] = parser.SelectItem{Expr: expr, Alias: f.Name()}
+	}
+
+	var having parser.Expr
+	if cmd.Having != nil {
+		expr, err := a.bind(cmd.Having, cols, grouped)
+		if err != nil {
+			return nil, err
+		}
+		having = expr
+	}
+
+	proj, err := compileProjection(items, a.cols)
+	if err != nil {
+		return nil, err
+	}
+	a.proj = proj
+
+	// ANU dievaluasi kana baris grup + hasil proyéksi, jadi alias SALAKU
+	// bisa dipaké (ANU n > 5)
+	if having != nil {
+		havingCols := append([]schema.Column(nil), a.cols...)
+		for i, f := range fields {
+			c := proj.exprs[i].column()
+			c.Name = ""
+			if f.Alias != "" {
+				c.Name = f.Alias
+			}
+			havingCols = append(havingCols, c)
+		}
+		if a.having, err = compileWhere(having, havingCols); err != nil {
+			return nil, err
+		}
+	}
+	return a, nil
+}
+
+// bind ngaganti unggal fungsi agrégat dina e ku kolom virtualna, sarta
+// mastikeun kolom tabel di luar fungsi agrégat aya dina KUMPULKEUN DUMASAR.
+func (a *aggregatePlan) bind(e parser.Expr, cols []schema.Column, grouped map[int]bool) (parser.Expr, error) {
+	switch x := e.(type) {
+	case *parser.Aggregate:
+		name := x.String()
+		if !a.seen[name] {
+			fn, err := compileAggFunc(x, cols)
+			if err != nil {
+				return nil, err
+			}
+			a.seen[name] = true
+			a.funcs = append(a.funcs, fn)
+			a.cols = append(a.cols, schema.Column{Name: name, Type: fn.typ})
+		}
+		return &parser.Ident{Name: name}, nil
+
+	case *parser.Ident:
+		if pos := findColumn(x.Name, cols); pos >= 0 && !grouped[pos] {
+			return nil, fmt.Errorf("kolom '%s' kudu di jero fungsi agrégat atawa aya dina KUMPULKEUN DUMASAR", x.Name)
+		}
+
+	case *parser.Binary:
+		left, err := a.bind(x.Left, cols, grouped)
+		if err != nil {
+			return nil, err
+		}
+		right, err := a.bind(x.Right, cols, grouped)
+		if err != nil {
+			return nil, err
+		}
+		return &parser.Binary{Op: x.Op, Left: left, Right: right}, nil
+
+	case *parser.Not:
+		inner, err := a.bind(x.X, cols, grouped)
+		if err != nil {
+			return nil, err
+		}
+		return &parser.Not{X: inner}, nil
+	}
+	return e, nil
+}
+
+func compileAggFunc(x *parser.Aggregate, cols []schema.Column) (aggFunc, error) {
+	fn := aggFunc{name: x.Func}
+	if x.Arg == nil {
+		fn.typ = "INT"
+		return fn, nil
+	}
+
+	arg, err := compileScalar(x.Arg, cols)
+	if err != nil {
+		return aggFunc{}, err
+	}
+	fn.arg = &arg
+
+	numeric := arg.typ == "" || arg.typ == "INT" || arg.typ == "FLOAT"
+	switch x.Func {
+	case "ITUNG":
+		fn.typ = "INT"
+	case "JUMLAH", "RATA":
+		if !numeric {
+			return aggFunc{}, fmt.Errorf("%s butuh nilai angka, '%s' tipena %s", x.Func, x.Arg, arg.typ)
+		}
+		fn.typ = "FLOAT"
+		if x.Func == "JUMLAH" && arg.typ == "INT" {
+			fn.typ = "INT"
+		}
+	default:
+		fn.typ = arg.typ
+	}
+	return fn, nil
+}
+
+func hasAggregate(fields []parser.SelectItem) bool {
+	for _, f := range fields {
+		if !f.Star && containsAggregate(f.Expr) {
+			return true
+		}
+	}
+	return false
+}
+
+func containsAggregate(e parser.Expr) bool {
+	switch x := e.(type) {
+	case *parser.Aggregate:
+		return true
+	case *parser.Binary:
+		return containsAggregate(x.Left) || containsAggregate(x.Right)
+	case *parser.Not:
+		return containsAggregate(x.X)
+	}
+	return false
+}
+
+// group nyaéta baris-baris nu kolom KUMPULKEUN DUMASAR-na sarua.
+type group struct {
+	sample []string // baris mimiti
+	accs   []accumulator
+}
+
+// execAggregate maca baris nu cocog jeung DIMANA, ngumpulkeun jadi grup,
+// ngitung agrégatna, nyaring ku ANU, tuluy nerapkeun RUNTUYKEUN / LIWATAN /
+// SAKADAR kana baris hasil.
+func execAggregate(cmd *parser.Command, s *schema.Definition, t storage.Table, pred predicate, a *aggregatePlan) (*ExecutionResult, error) {
+	plan, err := planSelect(scanCommand(cmd), s, t)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := plan.open(t)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	groups := make(map[string]*group)
+	var order []*group
+
+	// tanpa KUMPULKEUN DUMASAR salawasna aya hiji grup, sanajan kosong
+	// (ITUNG(*) = 0)
+	if a.groupBy == nil {
+		g := &group{accs: a.newGroup()}
+		groups[""] = g
+		order = append(order, g)
+	}
+
+	for it.Next() {
+		row := it.Row()
+		if pred != nil {
+			ok, err := pred(row)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+
+		key := a.groupKey(row)
+		g, ok := groups[key]
+		if !ok {
+			g = &group{sample: row, accs: a.newGroup()}
+			groups[key] = g
+			order = append(order, g)
+		}
+		if g.sample == nil {
+			g.sample = row
+		}
+		for _, acc := range g.accs {
+			if err := acc.add(row); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+
+	a.sortGroups(order)
+
+	var rows [][]string
+	for _, g := range order {
+		row := a.groupRow(g.sample, g.accs)
+		out, err := a.proj.apply(row)
+		if err != nil {
+			return nil, err
+		}
+
+		if a.having != nil {
+			ok, err := a.having(append(row, out...))
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
+			}
+		}
+		rows = append(rows, out)
+	}
+
+	if err := a.sortRows(cmd, rows); err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{Columns: a.proj.names, Rows: pageRows(rows, cmd.Offset, cmd.Limit), types: a.proj.types()}, nil
+}
+
+// groupKey ngahijikeun nilai kolom KUMPULKEUN DUMASAR. Nilai angka
+// dibakukeun heula, jadi "7" jeung "07" (INT) asup ka grup nu sarua.
+func (a *aggregatePlan) groupKey(row []string) string {
+	var b strings.Builder
+	for _, pos := range a.groupBy {
+		v := ""
+		if pos < len(row) {
+			v = canonicalValue(row[pos], a.cols[pos].Type)
+		}
+		b.WriteString(v)
+		b.WriteByte(0)
+	}
+	return b.String()
+}
+
+func canonicalValue(v, typ string) string {
+	switch typ {
+	case "INT":
+		if n, err := strconv.ParseInt(v, 10, 64); err == nil {
+			return strconv.FormatInt(n, 10)
+		}
+	case "FLOAT":
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			return strconv.FormatFloat(f, 'f', -1, 64)
+		}
+	}
+	return v
+}
+
+// sortGroups ngurutkeun grup dumasar kolom KUMPULKEUN DUMASAR numutkeun
+// tipena (INT 2 saméméh 10), jadi hasilna tetep sanajan tanpa RUNTUYKEUN.
+func (a *aggregatePlan) sortGroups(order []*group) {
+	sort.SliceStable(order, func(i, j int) bool {
+		for _, pos := range a.groupBy {
+			if c := a.cols[pos].Compare(order[i].sample[pos], order[j].sample[pos]); c != 0 {
+				return c < 0
+			}
+		}
+		return false
+	})
+}
+
+// scanCommand: TINGALI nu ngan maca baris nu cocog jeung DIMANA (tanpa
+// RUNTUYKEUN / SAKADAR), pikeun planner saméméh agrégasi.
+func scanCommand(cmd *parser.Command) *parser.Command {
+	return &parser.Command{Type: parser.CmdSelect, Table: cmd.Table, Where: cmd.Where, Limit: -1}
+}
+
+// sortRows ngurutkeun baris hasil numutkeun kolom hasil RUNTUYKEUN (ngaran
+// kolom atawa alias).
+func (a *aggregatePlan) sortRows(cmd *parser.Command, rows [][]string) error {
+	keys := make([]orderKey, len(cmd.OrderBy))
+	for i, o := range cmd.OrderBy {
+		idx := indexOf(o.Column, a.proj.names)
+		if idx == -1 {
+			return fmt.Errorf("kolom RUNTUYKEUN '%s' kudu aya dina hasil (%s)", o.Column, strings.Join(a.proj.names, ", "))
+		}
+		keys[i] = orderKey{pos: idx, col: a.proj.exprs[idx].column(), desc: o.Desc}
+	}
+	sortRows(rows, keys)
+	return nil
+}
+
+// steps ngajelaskeun agrégasi pikeun JELASKEUN.
+func (a *aggregatePlan) steps(cmd *parser.Command, ex *explainer) {
+	var funcs []string
+	for _, c := range a.cols[len(a.cols)-len(a.funcs):] {
+		funcs = append(funcs, c.Name)
+	}
+	if cmd.GroupBy != nil {
+		funcs = append([]string{"KUMPULKEUN DUMASAR " + strings.Join(cmd.GroupBy, ", ")}, funcs...)
+	}
+	ex.add("AGGREGATE", strings.Join(funcs, ", "))
+
+	if cmd.Having != nil {
+		ex.add("FILTER", "ANU "+cmd.Having.String())
+	}
+	if len(cmd.OrderBy) > 0 {
+		ex.add("SORT", fmt.Sprintf("RUNTUYKEUN %s (hasil grup)", describeOrder(cmd.OrderBy)))
+	}
+	if cmd.Limit > 0 || cmd.Offset > 0 {
+		detail := fmt.Sprintf("LIWATAN %d", cmd.Offset)
+		if cmd.Limit > 0 {
+			detail += fmt.Sprintf(", SAKADAR %d", cmd.Limit)
+		}
+		ex.add("LIMIT", detail+" (hasil grup)")
+	}
+	ex.add("PROJECT", strings.Join(a.proj.names, ", "))
+}
+
+// newGroup nyieun akumulator pikeun hiji grup.
+func (a *aggregatePlan) newGroup() []accumulator {
+	accs := make([]accumulator, len(a.funcs))
+	for i, fn := range a.funcs {
+		accs[i] = newAccumulator(fn)
+	}
+	return accs
+}
+
+// groupRow: baris conto grup (kolom tabel) + hasil unggal agrégat.
+func (a *aggregatePlan) groupRow(sample []string, accs []accumulator) []string {
+	row := make([]string, 0, len(a.cols))
+	if sample == nil {
+		sample = make([]string, len(a.cols)-len(a.funcs))
+	}
+	row = append(row, sample...)
+	for _, acc := range accs {
+		row = append(row, acc.result())
+	}
+	return row
+}
+
+// =======================
+// AKUMULATOR
+// =======================
+
+type accumulator interface {
+	add(row []string) error
+	result() string
+}
+
+func newAccumulator(fn aggFunc) accumulator {
+	switch fn.name {
+	case "ITUNG":
+		return &countAcc{arg: fn.arg}
+	case "JUMLAH":
+		return &sumAcc{arg: fn.arg, integer: fn.typ == "INT"}
+	case "RATA":
+		return &sumAcc{arg: fn.arg, average: true}
+	case "PANGLEUTIKNA":
+		return &extremeAcc{arg: fn.arg, col: fn.arg.column(), want: -1}
+	default:
+		return &extremeAcc{arg: fn.arg, col: fn.arg.column(), want: 1}
+	}
+}
+
+// countAcc: ITUNG(*) ngitung baris; ITUNG(x) ngitung nilai nu teu kosong.
+type countAcc struct {
+	arg *scalar
+	n   int
+}
+
+func (c *countAcc) add(row []string) error {
+	if c.arg == nil {
+		c.n++
+		return nil
+	}
+	v, err := c.arg.eval(row)
+	if err != nil {
+		return err
+	}
+	if v != "" {
+		c.n++
+	}
+	return nil
+}
+
+func (c *countAcc) result() string { return strconv.Itoa(c.n) }
+
+// sumAcc: JUMLAH jeung RATA. Kolom INT dijumlahkeun salaku int64 (teu
+// leungit presisi), sésana float64. Nilai kosong dilewat.
+type sumAcc struct {
+	arg     *scalar
+	integer bool
+	average bool
+
+	n     int
+	ints  int64
+	float float64
+}
+
+func (s *sumAcc) add(row []string) error {
+	v, err := s.arg.eval(row)
+	if err != nil || v == "" {
+		return err
+	}
+	if s.integer {
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return fmt.Errorf("nilai '%s' lain angka", v)
+		}
+		s.ints += n
+	} else {
+		f, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return fmt.Errorf("nilai '%s' lain angka", v)
+		}
+		s.float += f
+	}
+	s.n++
+	return nil
+}
+
+// result: kosong lamun euweuh nilai (JUMLAH / RATA tina sét kosong).
+func (s *sumAcc) result() string {
+	switch {
+	case s.n == 0:
+		return ""
+	case s.integer:
+		return strconv.FormatInt(s.ints, 10)
+	case s.average:
+		return strconv.FormatFloat(s.float/float64(s.n), 'f', -1, 64)
+	}
+	return strconv.FormatFloat(s.float, 'f', -1, 64)
+}
+
+// extremeAcc: PANGLEUTIKNA (want -1) jeung PANGGEDENA (want 1), dibandingkeun
+// numutkeun tipe kolom (DATE, INT, ...).
+type extremeAcc struct {
+	arg  *scalar
+	col  schema.Column
+	want int
+
+	best string
+	has  bool
+}
+
+func (m *extremeAcc) add(row []string) error {
+	v, err := m.arg.eval(row)
+	if err != nil || v == "" {
+		return err
+	}
+	if !m.has || m.col.Compare(v, m.best)*m.want > 0 {
+		m.best, m.has = v, true
+	}
+	return nil
+}
+
+func (m *extremeAcc) result() string { return m.best }
@@ -0,0 +1,190 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"unicode"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// SIMPEN / DAMEL ... TINGALI
+// =======================
+//
+//	SIMPEN arsip TINGALI id, nama TI pegawai DIMANA aktif = true
+//	DAMEL arsip_2026 SALAKU TINGALI * TI pegawai DIMANA masuk >= 2026-01-01
+//
+// TINGALI dijalankeun ku execSelect (kaasup GABUNG, agrégat, RUNTUYKEUN),
+// hasilna dianggap saperti SIMPEN ... NILAI: dipariksa sakabéhna heula,
+// tuluy ditulis sakaligus.
+
+// selectRows ngajalankeun TINGALI cmd.Source, mariksa tipe kolom hasilna
+// ngalawan tabel tujuan, sarta mulangkeun salinan cmd nu Rows-na hasil éta.
+func selectRows(cmd *parser.Command, s *schema.Definition, user *auth.User, store storage.Engine) (*parser.Command, error) {
+	res, err := execSelect(cmd.Source, user, store)
+	if err != nil {
+		return nil, err
+	}
+
+	targets := s.Columns
+	if cmd.Columns != nil {
+		targets = make([]schema.Column, len(cmd.Columns))
+		for i, name := range cmd.Columns {
+			pos := findColumn(name, s.Columns)
+			if pos < 0 {
+				return nil, fmt.Errorf("kolom '%s' teu kapanggih", name)
+			}
+			targets[i] = s.Columns[pos]
+		}
+	}
+	if len(res.types) != len(targets) {
+		return nil, fmt.Errorf("TINGALI mulangkeun %d kolom, tabel '%s' butuh %d", len(res.types), cmd.Table, len(targets))
+	}
+	for i, src := range res.types {
+		if !compatibleType(src, targets[i]) {
+			return nil, fmt.Errorf("kolom '%s' (%s) teu cocog jeung kolom '%s' (%s)",
+				res.Columns[i], src.TypeString(), targets[i].Name, targets[i].TypeString())
+		}
+	}
+
+	out := *cmd
+	out.Rows = res.Rows
+	out.Nulls = make([][]bool, len(res.Rows))
+	for i, row := range res.Rows {
+		out.Nulls[i] = nulls(row)
+	}
+	return &out, nil
+}
+
+// nulls nandaan nilai kosong dina baris nu asalna tina data (lain tina
+// query) salaku null.
+func nulls(row []string) []bool {
+	null := make([]bool, len(row))
+	for i, v := range row {
+		null[i] = v == ""
+	}
+	return null
+}
+
+// compatibleType: naha nilai kolom src bisa asup ka kolom dst. Tipe nu teu
+// dipikanyaho (nilai konstan) jeung wates ENUM/CHAR dipariksa per baris.
+func compatibleType(src, dst schema.Column) bool {
+	text := func(t string) bool { return t == "STRING" || t == "TEXT" || t == "CHAR" }
+	switch {
+	case src.Type == "", src.Type == dst.Type:
+		return true
+	case dst.Type == "STRING", dst.Type == "TEXT":
+		return true
+	case text(src.Type) && text(dst.Type):
+		return true
+	case src.Type == "INT" && dst.Type == "FLOAT":
+		return true
+	}
+	return false
+}
+
+// execCreateAs: DAMEL <tabel> SALAKU TINGALI ... Schema tabel anyar dicokot
+// tina ngaran jeung tipe kolom hasil TINGALI.
+func execCreateAs(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	if _, err := schema.Load(user.Database, cmd.Table); err == nil {
+		return nil, fmt.Errorf("tabel '%s' geus aya", cmd.Table)
+	}
+
+	res, err := execSelect(cmd.Source, user, store)
+	if err != nil {
+		return nil, err
+	}
+
+	def, err := derivedSchema(res)
+	if err != nil {
+		return nil, err
+	}
+	for i, row := range res.Rows {
+		if err := def.ValidateRow(row, nulls(row)); err != nil {
+			return nil, fmt.Errorf("baris ka-%d: %w", i+1, err)
+		}
+	}
+
+	fields := make([]string, len(def.Columns))
+	for i, c := range def.Columns {
+		fields[i] = c.Name + ":" + c.TypeString()
+	}
+	perms := map[string][]string{
+		"read":  {"user", "admin", "supermaung"},
+		"write": {"admin", "supermaung"},
+	}
+	if err := schema.Create(user.Database, cmd.Table, fields, perms); err != nil {
+		return nil, err
+	}
+
+	if err := insertAll(store, user.Database, cmd.Table, res.Rows); err != nil {
+		// tabel nu satengah jadi dipiceun deui (data heula, tuluy schema,
+		// sarua jeung PICEUN), jadi DAMEL bisa diulang
+		_ = store.Drop(user.Database, cmd.Table)
+		_ = schema.Drop(user.Database, cmd.Table)
+		return nil, err
+	}
+
+	return &ExecutionResult{
+		Message: fmt.Sprintf("✅ Tabel '%s' parantos didamel tina hasil TINGALI (%d data)", cmd.Table, len(res.Rows)),
+	}, nil
+}
+
+func insertAll(store storage.Engine, database, table string, rows [][]string) error {
+	if len(rows) == 0 {
+		return nil
+	}
+	t, err := store.Open(database, table)
+	if err != nil {
+		return err
+	}
+	return t.Insert(rows...)
+}
+
+// derivedSchema nyusun kolom tabel anyar tina hasil TINGALI. Kolom itungan
+// atawa agrégat kudu dibéré ngaran ku SALAKU; nilai konstan jadi STRING.
+// Kolom GABUNG dicokot ngaranna wungkul (tanpa ngaran tabel).
+func derivedSchema(res *ExecutionResult) (*schema.Definition, error) {
+	if len(res.types) == 0 {
+		return nil, errors.New("TINGALI teu mulangkeun kolom")
+	}
+
+	def := &schema.Definition{}
+	seen := make(map[string]bool)
+	for i, name := range res.Columns {
+		// kolom GABUNG: mhs.nama -> nama
+		if _, bare, ok := strings.Cut(name, "."); ok && validName(bare) {
+			name = bare
+		}
+		if !validName(name) {
+			return nil, fmt.Errorf("kolom hasil '%s' teu bisa jadi ngaran kolom, paké SALAKU <ngaran>", name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("kolom hasil '%s' aya leuwih ti sakali, paké SALAKU <ngaran>", name)
+		}
+		seen[name] = true
+
+		c := res.types[i]
+		if c.Type == "" {
+			c = schema.Column{Type: "STRING"}
+		}
+		def.Columns = append(def.Columns, schema.Column{Name: name, Type: c.Type, Args: c.Args})
+	}
+	return def, nil
+}
+
+// validName: hurup, angka jeung _ wungkul (teu bisa ngandung ':',
+// '|', '.', kurung, ...).
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	return strings.IndexFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) < 0
+}
@@ -0,0 +1,207 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// DDL: PICEUN / KOSONGKEUN / GANTI NGARAN
+// =======================
+//
+//	PICEUN TABEL [LAMUN AYA] arsip
+//	PICEUN DATABASE [LAMUN AYA] kantor_lami
+//	KOSONGKEUN [TABEL] [LAMUN AYA] log
+//	GANTI NGARAN TABEL [LAMUN AYA] pegawai JADI karyawan
+//	GANTI NGARAN DATABASE [LAMUN AYA] kantor JADI kantor_pusat
+//
+// Tabel: PICEUN & GANTI NGARAN butuh role admin sarta hak nulis tabel;
+// KOSONGKEUN cukup hak nulis (sarua jeung MICEUN ... SADAYANA). Database:
+// ngan supermaung.
+//
+// PICEUN miceun file data & indeks heula, kakara schema: lamun gagal di
+// tengah, schema masih aya jadi paréntahna bisa diulang, sarta DAMEL
+// engké teu manggihan file data sésa tabel heubeul.
+
+// isDatabaseDDL: paréntah nu teu butuh database nu dipilih.
+func isDatabaseDDL(t parser.CommandType) bool {
+	return t == parser.CmdDropDatabase || t == parser.CmdRenameDatabase
+}
+
+// checkDDL mariksa hak aksés, ayana objék jeung ngaran anyar. skip=true
+// hartina objékna teu aya tapi aya LAMUN AYA, jadi paréntahna dilewat.
+func checkDDL(cmd *parser.Command, user *auth.User) (skip bool, err error) {
+	if isDatabaseDDL(cmd.Type) {
+		if err := user.HasRole("supermaung"); err != nil {
+			return false, err
+		}
+		if !storage.DatabaseExists(cmd.Database) {
+			if cmd.IfExists {
+				return true, nil
+			}
+			return false, fmt.Errorf("database '%s' teu kapanggih", cmd.Database)
+		}
+		if cmd.Type == parser.CmdRenameDatabase {
+			if !validName(cmd.NewName) {
+				return false, fmt.Errorf("ngaran database '%s' teu valid", cmd.NewName)
+			}
+			if storage.DatabaseExists(cmd.NewName) {
+				return false, fmt.Errorf("database '%s' geus aya", cmd.NewName)
+			}
+		}
+		return false, nil
+	}
+
+	if !schema.Exists(user.Database, cmd.Table) {
+		if cmd.IfExists {
+			return true, nil
+		}
+		return false, fmt.Errorf("tabel '%s' teu kapanggih", cmd.Table)
+	}
+	s, err := schema.Load(user.Database, cmd.Table)
+	if err != nil {
+		return false, err
+	}
+	if !s.Can(user.Role, "write") {
+		return false, errors.New("teu boga hak nulis")
+	}
+	if cmd.Type != parser.CmdTruncate {
+		if err := user.HasRole("admin"); err != nil {
+			return false, err
+		}
+	}
+
+	if cmd.Type == parser.CmdRenameTable {
+		if !validName(cmd.NewName) {
+			return false, fmt.Errorf("ngaran tabel '%s' teu valid", cmd.NewName)
+		}
+		if schema.Exists(user.Database, cmd.NewName) {
+			return false, fmt.Errorf("tabel '%s' geus aya", cmd.NewName)
+		}
+	}
+	return false, nil
+}
+
+// ddlObject: "tabel 'x'" atawa "database 'x'".
+func ddlObject(cmd *parser.Command) string {
+	if isDatabaseDDL(cmd.Type) {
+		return fmt.Sprintf("database '%s'", cmd.Database)
+	}
+	return fmt.Sprintf("tabel '%s'", cmd.Table)
+}
+
+func execDDL(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	skip, err := checkDDL(cmd, user)
+	if err != nil {
+		return nil, err
+	}
+	if skip {
+		return &ExecutionResult{Message: "✅ Dilewat: " + ddlObject(cmd) + " teu aya"}, nil
+	}
+
+	switch cmd.Type {
+	case parser.CmdDropTable:
+		return execDropTable(cmd, user, store)
+	case parser.CmdTruncate:
+		return execTruncate(cmd, user, store)
+	case parser.CmdRenameTable:
+		return execRenameTable(cmd, user, store)
+	case parser.CmdDropDatabase:
+		return execDropDatabase(cmd, store)
+	default:
+		return execRenameDatabase(cmd, store)
+	}
+}
+
+func execDropTable(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	if err := store.Drop(user.Database, cmd.Table); err != nil {
+		return nil, err
+	}
+	if err := schema.Drop(user.Database, cmd.Table); err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{Message: fmt.Sprintf("✅ Tabel '%s' parantos dipiceun", cmd.Table)}, nil
+}
+
+// execTruncate miceun sakabéh baris; schema jeung indeks tetep aya.
+// Bisa di jero transaksi.
+func execTruncate(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	t, err := store.Open(user.Database, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+	n, err := t.Delete(func([]string) (bool, error) { return true, nil })
+	if err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{Message: fmt.Sprintf("✅ Tabel '%s' dikosongkeun (%d data dipiceun)", cmd.Table, n)}, nil
+}
+
+func execRenameTable(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	if err := renameTable(store, user.Database, cmd.Table, user.Database, cmd.NewName); err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{Message: fmt.Sprintf("✅ Tabel '%s' diganti ngaranna jadi '%s'", cmd.Table, cmd.NewName)}, nil
+}
+
+// renameTable mindahkeun schema heula, tuluy baris & indeks. Lamun nu
+// kadua gagal, schema dibalikkeun deui.
+func renameTable(store storage.Engine, database, table, newDatabase, newTable string) error {
+	if err := schema.Rename(database, table, newDatabase, newTable); err != nil {
+		return err
+	}
+	if err := store.Rename(database, table, newDatabase, newTable); err != nil {
+		_ = schema.Rename(newDatabase, newTable, database, table)
+		return err
+	}
+	return nil
+}
+
+// execDropDatabase miceun tabel hiji-hiji, grant & aksés user, kakara
+// diréktorina. Unggal léngkah bisa diulang (tabel nu geus dipiceun
+// dilewat), jadi lamun gagal di tengah, PICEUN DATABASE deui ngaréngsékeun.
+func execDropDatabase(cmd *parser.Command, store storage.Engine) (*ExecutionResult, error) {
+	tables, err := schema.Tables(cmd.Database)
+	if err != nil {
+		return nil, err
+	}
+	for _, table := range tables {
+		if err := store.Drop(cmd.Database, table); err != nil {
+			return nil, fmt.Errorf("tabel '%s': %w", table, err)
+		}
+		if err := schema.Drop(cmd.Database, table); err != nil {
+			return nil, fmt.Errorf("tabel '%s': %w", table, err)
+		}
+	}
+
+	if err := auth.RevokeDatabase(cmd.Database); err != nil {
+		return nil, err
+	}
+	if err := storage.DropDatabase(cmd.Database); err != nil {
+		return nil, err
+	}
+	return &ExecutionResult{Message: fmt.Sprintf("✅ Database '%s' parantos dipiceun (%d tabel)", cmd.Database, len(tables))}, nil
+}
+
+// execRenameDatabase ngaganti ngaran diréktori database (hiji rename),
+// tuluy ngaganti ngaranna dina grant & aksés user. Lamun nu kadua gagal,
+// diréktorina dibalikkeun deui.
+func execRenameDatabase(cmd *parser.Command, store storage.Engine) (*ExecutionResult, error) {
+	tables, err := schema.Tables(cmd.Database)
+	if err != nil {
+		return nil, err
+	}
+	if err := store.RenameDatabase(cmd.Database, cmd.NewName); err != nil {
+		return nil, err
+	}
+	if err := auth.RenameDatabase(cmd.Database, cmd.NewName); err != nil {
+		_ = store.RenameDatabase(cmd.NewName, cmd.Database)
+		return nil, err
+	}
+	return &ExecutionResult{Message: fmt.Sprintf("✅ Database '%s' diganti ngaranna jadi '%s' (%d tabel)", cmd.Database, cmd.NewName, len(tables))}, nil
+}
@@ -0,0 +1,677 @@
+package executor
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+)
+
+// =======================
+// DIMANA EVALUATOR
+// =======================
+//
+// Tangkal DIMANA ti parser "dibeungkeut" heula ka kolom-kolom tabel (sakali
+// per query), hasilna predicate nu dipaké ku TINGALI, OMEAN jeung MICEUN.
+
+// predicate mulangkeun true lamun baris cocog jeung DIMANA.
+type predicate func(row []string) (bool, error)
+
+// scalar nyaéta éksprési nilai nu geus dibeungkeut ka kolom.
+type scalar struct {
+	typ  string         // tipe kolom (INT, DATE, ...); "" = teu dipikanyaho
+	col  *schema.Column // lamun éksprésina ngan hiji kolom
+	eval func(row []string) (string, error)
+}
+
+// column: kolom pikeun ngabandingkeun nilai scalar (sort, PANGGEDENA, ...).
+func (s scalar) column() schema.Column {
+	if s.col != nil {
+		return *s.col
+	}
+	return schema.Column{Type: s.typ}
+}
+
+// compileWhere ngabeungkeut DIMANA ka kolom cols. where == nil hartina
+// sakabéh baris cocog (predicate nil).
+func compileWhere(where parser.Expr, cols []schema.Column) (predicate, error) {
+	if where == nil {
+		return nil, nil
+	}
+	return compilePredicate(where, cols)
+}
+
+func compilePredicate(e parser.Expr, cols []schema.Column) (predicate, error) {
+	switch x := e.(type) {
+	case *parser.Not:
+		inner, err := compilePredicate(x.X, cols)
+		if err != nil {
+			return nil, err
+		}
+		return func(row []string) (bool, error) {
+			ok, err := inner(row)
+			return !ok, err
+		}, nil
+
+	case *parser.Binary:
+		switch x.Op {
+		case "SARENG", "ATAWA":
+			left, err := compilePredicate(x.Left, cols)
+			if err != nil {
+				return nil, err
+			}
+			right, err := compilePredicate(x.Right, cols)
+			if err != nil {
+				return nil, err
+			}
+			and := x.Op == "SARENG"
+			return func(row []string) (bool, error) {
+				ok, err := left(row)
+				if err != nil || ok != and {
+					// SARENG: kénca false -> false; ATAWA: kénca true -> true
+					return ok, err
+				}
+				return right(row)
+			}, nil
+		case "+", "-", "*", "/", "%":
+		case "COCOG":
+			return compileRegexp(x, cols)
+		default:
+			return compileComparison(x, cols)
+		}
+
+	case *parser.In:
+		return compileIn(x, cols)
+	case *parser.Between:
+		return compileBetween(x, cols)
+	case *parser.IsEmpty:
+		val, err := compileScalar(x.X, cols)
+		if err != nil {
+			return nil, err
+		}
+		return func(row []string) (bool, error) {
+			v, err := val.eval(row)
+			return strings.TrimSpace(v) == "", err
+		}, nil
+	}
+
+	return nil, fmt.Errorf("DIMANA kudu babandingan (contona kolom = nilai), lain '%s'", e)
+}
+
+// compileComparison: kecap di kénca kudu kolom; kecap di katuhu jadi kolom
+// lamun aya kolom nu ngaranna kitu, lamun euweuh jadi nilai.
+func compileComparison(x *parser.Binary, cols []schema.Column) (predicate, error) {
+	left, err := compileScalar(x.Left, cols)
+	if err != nil {
+		return nil, err
+	}
+	right, err := compileOperand(x.Right, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	// tipe babandingan dicokot ti kolom (kénca heula)
+	col := left.column()
+	if left.typ == "" {
+		col = right.column()
+	}
+
+	// nilai tetep dipariksa numutkeun kolom, sarua jeung DI / ANTARA
+	if x.Op != "JIGA" {
+		switch {
+		case left.col != nil && isConstant(x.Right, cols):
+			v, _ := right.eval(nil)
+			err = checkLiteral(*left.col, v)
+		case right.col != nil && isConstant(x.Left, cols):
+			v, _ := left.eval(nil)
+			err = checkLiteral(*right.col, v)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	op := x.Op
+	return func(row []string) (bool, error) {
+		a, err := left.eval(row)
+		if err != nil {
+			return false, err
+		}
+		b, err := right.eval(row)
+		if err != nil {
+			return false, err
+		}
+		return compareValues(a, op, b, col), nil
+	}, nil
+}
+
+// checkLiteral: v kudu sah pikeun kolom col. Kolom INT ogé narima
+// desimal, sabab dibandingkeun salaku angka (umur > 20.5).
+func checkLiteral(col schema.Column, v string) error {
+	if col.Type == "INT" && strings.TrimSpace(v) != "" {
+		if _, err := strconv.ParseFloat(strings.TrimSpace(v), 64); err != nil {
+			return fmt.Errorf("kolom '%s' kudu INT (angka)", col.Name)
+		}
+		return nil
+	}
+	return col.Validate(v)
+}
+
+// compileOperand: sisi katuhu babandingan. Kecap nu lain ngaran kolom
+// dianggap nilai (DIMANA divisi = IT).
+func compileOperand(e parser.Expr, cols []schema.Column) (scalar, error) {
+	if id, ok := e.(*parser.Ident); ok {
+		pos, err := lookupColumn(id.Name, cols)
+		if err != nil {
+			return scalar{}, err
+		}
+		if pos < 0 {
+			return constant(id.Name), nil
+		}
+	}
+	return compileScalar(e, cols)
+}
+
+// compileScalar ngabeungkeut éksprési nilai (kolom, nilai, itungan).
+func compileScalar(e parser.Expr, cols []schema.Column) (scalar, error) {
+	switch x := e.(type) {
+	case *parser.Ident:
+		pos, err := lookupColumn(x.Name, cols)
+		if err != nil {
+			return scalar{}, err
+		}
+		if pos < 0 {
+			return scalar{}, fmt.Errorf("kolom '%s' teu kapanggih", x.Name)
+		}
+		return scalar{
+			typ: cols[pos].Type,
+			col: &cols[pos],
+			eval: func(row []string) (string, error) {
+				if pos >= len(row) {
+					return "", nil
+				}
+				return row[pos], nil
+			},
+		}, nil
+
+	case *parser.Literal:
+		return constant(x.Value), nil
+
+	case *parser.Aggregate:
+		return scalar{}, fmt.Errorf("fungsi agrégat %s ngan bisa dina daptar kolom TINGALI", x)
+
+	case *parser.Binary:
+		switch x.Op {
+		case "+", "-", "*", "/", "%":
+			return compileArithmetic(x, cols)
+		}
+	}
+	return scalar{}, fmt.Errorf("'%s' teu bisa dipaké salaku nilai", e)
+}
+
+func constant(v string) scalar {
+	return scalar{eval: func([]string) (string, error) { return v, nil }}
+}
+
+// compileArithmetic: + - * / %. Hasilna INT lamun dua sisina wilangan
+// buleud (lain /), lamun henteu FLOAT.
+func compileArithmetic(x *parser.Binary, cols []schema.Column) (scalar, error) {
+	left, err := compileScalar(x.Left, cols)
+	if err != nil {
+		return scalar{}, err
+	}
+	right, err := compileScalar(x.Right, cols)
+	if err != nil {
+		return scalar{}, err
+	}
+	for _, side := range []struct {
+		e parser.Expr
+		s scalar
+	}{{x.Left, left}, {x.Right, right}} {
+		if side.s.typ != "" && side.s.typ != "INT" && side.s.typ != "FLOAT" {
+			return scalar{}, fmt.Errorf("'%s' (%s) lain angka", side.e, side.s.typ)
+		}
+	}
+
+	op := x.Op
+	typ := "FLOAT"
+	if isIntegral(x.Left, left) && isIntegral(x.Right, right) && op != "/" {
+		typ = "INT"
+	}
+
+	return scalar{
+		typ: typ,
+		eval: func(row []string) (string, error) {
+			a, err := left.eval(row)
+			if err != nil {
+				return "", err
+			}
+			b, err := right.eval(row)
+			if err != nil {
+				return "", err
+			}
+			return arithmetic(a, op, b, typ)
+		},
+	}, nil
+}
+
+func isIntegral(e parser.Expr, s scalar) bool {
+	if s.typ != "" {
+		return s.typ == "INT"
+	}
+	if lit, ok := e.(*parser.Literal); ok {
+		_, err := strconv.ParseInt(lit.Value, 10, 64)
+		return err == nil
+	}
+	return false
+}
+
+var errDivideByZero = errors.New("dibagi ku nol")
+
+func arithmetic(a, op, b, typ string) (string, error) {
+	if typ == "INT" {
+		x, errA := strconv.ParseInt(a, 10, 64)
+		y, errB := strconv.ParseInt(b, 10, 64)
+		if errA == nil && errB == nil {
+			switch op {
+			case "+":
+				return strconv.FormatInt(x+y, 10), nil
+			case "-":
+				return strconv.FormatInt(x-y, 10), nil
+			case "*":
+				return strconv.FormatInt(x*y, 10), nil
+			case "%":
+				if y == 0 {
+					return "", errDivideByZero
+				}
+				return strconv.FormatInt(x%y, 10), nil
+			}
+		}
+	}
+
+	x, err := strconv.ParseFloat(a, 64)
+	if err != nil {
+		return "", fmt.Errorf("nilai '%s' lain angka", a)
+	}
+	y, err := strconv.ParseFloat(b, 64)
+	if err != nil {
+		return "", fmt.Errorf("nilai '%s' lain angka", b)
+	}
+
+	var r float64
+	switch op {
+	case "+":
+		r = x + y
+	case "-":
+		r = x - y
+	case "*":
+		r = x * y
+	case "/", "%":
+		if y == 0 {
+			return "", errDivideByZero
+		}
+		if op == "/" {
+			r = x / y
+		} else {
+			r = math.Mod(x, y)
+		}
+	}
+	return strconv.FormatFloat(r, 'f', -1, 64), nil
+}
+
+// lookupColumn milarian posisi kolom name dina cols (-1 lamun euweuh). Dina
+// hasil GABUNG ngaran kolom make ngaran tabel (mahasiswa.id); ngaran tanpa
+// tabel (id) ogé bisa, salami ngan aya dina hiji tabel.
+func lookupColumn(name string, cols []schema.Column) (int, error) {
+	for i := range cols {
+		if cols[i].Name == name {
+			return i, nil
+		}
+	}
+	if table, _, ok := strings.Cut(name, "."); ok {
+		// tabel.kolom nu tabelna aya tapi kolomna euweuh
+		for i := range cols {
+			if strings.HasPrefix(cols[i].Name, table+".") {
+				return -1, fmt.Errorf("kolom '%s' teu kapanggih", name)
+			}
+		}
+		return -1, nil
+	}
+
+	found := -1
+	var matches []string
+	for i := range cols {
+		if _, col, ok := strings.Cut(cols[i].Name, "."); ok && col == name {
+			if found < 0 {
+				found = i
+			}
+			matches = append(matches, cols[i].Name)
+		}
+	}
+	if len(matches) > 1 {
+		return -1, fmt.Errorf("kolom '%s' ambigu (%s), paké <tabel>.%s", name, strings.Join(matches, ", "), name)
+	}
+	return found, nil
+}
+
+// findColumn sarua jeung lookupColumn, tapi ngaran nu ambigu = -1.
+func findColumn(name string, cols []schema.Column) int {
+	pos, err := lookupColumn(name, cols)
+	if err != nil {
+		return -1
+	}
+	return pos
+}
+
+// displayNames: ngaran kolom pikeun hasil. Kolom hasil GABUNG ditémbongkeun
+// tanpa ngaran tabel lamun teu ambigu (nama), lamun ambigu lengkep
+// (mahasiswa.id, nilai.id).
+func displayNames(cols []schema.Column) []string {
+	names := make([]string, len(cols))
+	for i, c := range cols {
+		names[i] = c.Name
+		if _, col, ok := strings.Cut(c.Name, "."); ok {
+			if pos, err := lookupColumn(col, cols); err == nil && pos == i {
+				names[i] = col
+			}
+		}
+	}
+	return names
+}
+
+// compareValues ngabandingkeun a jeung b numutkeun kolom col (tingali
+// orderValues), jadi sarua jeung ANTARA jeung RUNTUYKEUN: ENUM numutkeun
+// urutan dina definisi, INT/FLOAT salaku angka (umur > 20.5). Nilai nu teu
+// sah pikeun tipena teu cocog jeung naon waé.
+func compareValues(a, op, b string, col schema.Column) bool {
+	if strings.ToUpper(op) == "JIGA" {
+		return strings.Contains(strings.ToLower(a), strings.ToLower(b))
+	}
+
+	c, ok := orderValues(col, a, b)
+	if !ok {
+		return false
+	}
+	switch op {
+	case "=":
+		return c == 0
+	case "!=":
+		return c != 0
+	case ">":
+		return c > 0
+	case "<":
+		return c < 0
+	case ">=":
+		return c >= 0
+	case "<=":
+		return c <= 0
+	}
+	return false
+}
+
+// =======================
+// DI, ANTARA, COCOG
+// =======================
+
+// compileIn: <nilai> DI (a, b, c). Lamun sakabéh daptar nilai tetep, dijieun
+// set sakali per query.
+func compileIn(x *parser.In, cols []schema.Column) (predicate, error) {
+	val, err := compileScalar(x.X, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]scalar, len(x.List))
+	constants := val.typ != ""
+	for i, e := range x.List {
+		if items[i], err = compileBound(val, e, cols); err != nil {
+			return nil, err
+		}
+		constants = constants && isConstant(e, cols)
+	}
+
+	if constants {
+		// INT dibandingkeun salaku angka (sarua jeung orderValues), jadi
+		// umur DI (22.0) cocog jeung 22
+		typ := val.typ
+		if typ == "INT" {
+			typ = "FLOAT"
+		}
+		set := make(map[string]bool, len(items))
+		for _, it := range items {
+			v, _ := it.eval(nil)
+			set[canonicalValue(v, typ)] = true
+		}
+		return func(row []string) (bool, error) {
+			v, err := val.eval(row)
+			return err == nil && set[canonicalValue(v, typ)], err
+		}, nil
+	}
+
+	return func(row []string) (bool, error) {
+		a, err := val.eval(row)
+		if err != nil {
+			return false, err
+		}
+		for _, it := range items {
+			b, err := it.eval(row)
+			if err != nil {
+				return false, err
+			}
+			if compareValues(a, "=", b, val.column()) {
+				return true, nil
+			}
+		}
+		return false, nil
+	}, nil
+}
+
+// compileBetween: <nilai> ANTARA lo JEUNG hi, dua watesna kaasup. Dibandingkeun
+// numutkeun tipe kolom (ENUM numutkeun urutan dina definisi).
+func compileBetween(x *parser.Between, cols []schema.Column) (predicate, error) {
+	val, err := compileScalar(x.X, cols)
+	if err != nil {
+		return nil, err
+	}
+	low, err := compileBound(val, x.Low, cols)
+	if err != nil {
+		return nil, err
+	}
+	high, err := compileBound(val, x.High, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	col := val.column()
+	return func(row []string) (bool, error) {
+		var v [3]string
+		for i, s := range []scalar{val, low, high} {
+			var err error
+			if v[i], err = s.eval(row); err != nil {
+				return false, err
+			}
+		}
+		lo, okLo := orderValues(col, v[0], v[1])
+		hi, okHi := orderValues(col, v[0], v[2])
+		return okLo && okHi && lo >= 0 && hi <= 0, nil
+	}, nil
+}
+
+// compileBound ngabeungkeut nilai babandingan pikeun DI / ANTARA. Nilai
+// tetep dipariksa numutkeun tipe kolom (umur DI (1, dua) -> kasalahan).
+func compileBound(val scalar, e parser.Expr, cols []schema.Column) (scalar, error) {
+	s, err := compileOperand(e, cols)
+	if err != nil {
+		return scalar{}, err
+	}
+	if val.col != nil && isConstant(e, cols) {
+		v, _ := s.eval(nil)
+		if err := checkLiteral(*val.col, v); err != nil {
+			return scalar{}, err
+		}
+	}
+	return s, nil
+}
+
+// isConstant: e teu gumantung kana baris (euweuh kolom).
+func isConstant(e parser.Expr, cols []schema.Column) bool {
+	switch x := e.(type) {
+	case *parser.Literal:
+		return true
+	case *parser.Ident:
+		return findColumn(x.Name, cols) < 0
+	}
+	return false
+}
+
+// orderValues ngabandingkeun a jeung b numutkeun tipe col (-1, 0, 1). false
+// lamun salah sahijina lain nilai nu sah pikeun tipe éta.
+func orderValues(col schema.Column, a, b string) (int, bool) {
+	switch col.Type {
+	case "INT", "FLOAT":
+		x, errA := strconv.ParseFloat(a, 64)
+		y, errB := strconv.ParseFloat(b, 64)
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+		return cmp.Compare(x, y), true
+	case "DATE":
+		x, errA := time.Parse("2006-01-02", a)
+		y, errB := time.Parse("2006-01-02", b)
+		if errA != nil || errB != nil {
+			return 0, false
+		}
+		return x.Compare(y), true
+	case "ENUM":
+		x, y := slices.Index(col.Args, a), slices.Index(col.Args, b)
+		if x < 0 || y < 0 {
+			return 0, false
+		}
+		return cmp.Compare(x, y), true
+	case "BOOL":
+		if (a != "true" && a != "false") || (b != "true" && b != "false") {
+			return 0, false
+		}
+		return strings.Compare(a, b), true
+	case "":
+		if x, err := strconv.ParseFloat(a, 64); err == nil {
+			if y, err := strconv.ParseFloat(b, 64); err == nil {
+				return cmp.Compare(x, y), true
+			}
+		}
+	}
+	return strings.Compare(a, b), true
+}
+
+// compileRegexp: <nilai> COCOG 'pola' (regexp Go, contona '^A.*h$'). Pola
+// tetep di-compile sakali; pola tina kolom disimpen dina cache per query.
+func compileRegexp(x *parser.Binary, cols []schema.Column) (predicate, error) {
+	val, err := compileScalar(x.Left, cols)
+	if err != nil {
+		return nil, err
+	}
+	pattern, err := compileOperand(x.Right, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	if isConstant(x.Right, cols) {
+		p, _ := pattern.eval(nil)
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pola COCOG '%s' teu valid: %v", p, err)
+		}
+		return func(row []string) (bool, error) {
+			v, err := val.eval(row)
+			return err == nil && re.MatchString(v), err
+		}, nil
+	}
+
+	cache := make(map[string]*regexp.Regexp)
+	return func(row []string) (bool, error) {
+		v, err := val.eval(row)
+		if err != nil {
+			return false, err
+		}
+		p, err := pattern.eval(row)
+		if err != nil {
+			return false, err
+		}
+		re, ok := cache[p]
+		if !ok {
+			if re, err = regexp.Compile(p); err != nil {
+				return false, fmt.Errorf("pola COCOG '%s' teu valid: %v", p, err)
+			}
+			cache[p] = re
+		}
+		return re.MatchString(v), nil
+	}, nil
+}
+
+// =======================
+// PROYÉKSI (TINGALI <kolom>, ...)
+// =======================
+
+// projection nyaéta kolom-kolom hasil TINGALI.
+type projection struct {
+	names []string
+	exprs []scalar
+}
+
+// compileProjection ngabeungkeut daptar kolom TINGALI. fields == nil hartina
+// sadaya kolom (projection nil).
+func compileProjection(fields []parser.SelectItem, cols []schema.Column) (*projection, error) {
+	if fields == nil {
+		return nil, nil
+	}
+
+	p := &projection{}
+	for _, f := range fields {
+		if f.Star {
+			for i, name := range displayNames(cols) {
+				s, _ := compileScalar(&parser.Ident{Name: cols[i].Name}, cols)
+				p.names = append(p.names, name)
+				p.exprs = append(p.exprs, s)
+			}
+			continue
+		}
+
+		s, err := compileScalar(f.Expr, cols)
+		if err != nil {
+			return nil, err
+		}
+		p.names = append(p.names, f.Name())
+		p.exprs = append(p.exprs, s)
+	}
+	return p, nil
+}
+
+// types: tipe unggal kolom hasil proyéksi.
+func (p *projection) types() []schema.Column {
+	out := make([]schema.Column, len(p.exprs))
+	for i, s := range p.exprs {
+		out[i] = s.column()
+		out[i].Name = p.names[i]
+	}
+	return out
+}
+
+func (p *projection) apply(row []string) ([]string, error) {
+	out := make([]string, len(p.exprs))
+	for i, s := range p.exprs {
+		v, err := s.eval(row)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
@@ -0,0 +1,120 @@
+package executor
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+)
+
+var evalCols = []schema.Column{
+	{Name: "id", Type: "INT"},
+	{Name: "nama", Type: "STRING"},
+	{Name: "umur", Type: "INT"},
+	{Name: "prio", Type: "ENUM", Args: []string{"RENDAH", "SEDENG", "LUHUR"}},
+	{Name: "masuk", Type: "DATE"},
+}
+
+var evalRows = [][]string{
+	{"1", "Asep Sunandar", "30", "RENDAH", "2024-01-10"},
+	{"2", "Siti", "22", "SEDENG", "2023-05-01"},
+	{"3", "Dadang", "-5", "LUHUR", ""},
+	{"4", "", "", "SEDENG", "2022-12-31"},
+}
+
+// matchIDs mulangkeun id baris evalRows nu cocog jeung DIMANA where.
+func matchIDs(t *testing.T, where string) ([]string, error) {
+	t.Helper()
+	cmd, err := parser.Parse("TINGALI t DIMANA " + where)
+	if err != nil {
+		t.Fatalf("%s: %v", where, err)
+	}
+	pred, err := compileWhere(cmd.Where, evalCols)
+	if err != nil {
+		return nil, err
+	}
+	var ids []string
+	for _, row := range evalRows {
+		ok, err := pred(row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ids = append(ids, row[0])
+		}
+	}
+	return ids, nil
+}
+
+func TestWhereDecimalOnIntColumn(t *testing.T) {
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		{"umur > 20.5", []string{"1", "2"}},
+		{"umur = 30.0", []string{"1"}},
+		{"umur ANTARA 20.5 JEUNG 30", []string{"1", "2"}},
+		{"umur DI (22.0, 30)", []string{"1", "2"}},
+		{"umur DI (22.5)", nil},
+	}
+	for _, tt := range tests {
+		got, err := matchIDs(t, tt.where)
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: meunang %v, kuduna %v", tt.where, got, tt.want)
+		}
+	}
+
+	for _, where := range []string{"umur > abc", "umur ANTARA 1 JEUNG dua", "umur DI (20, dua)"} {
+		if _, err := matchIDs(t, where); err == nil {
+			t.Errorf("%s: kuduna error", where)
+		}
+	}
+}
+
+func TestWhereOperators(t *testing.T) {
+	tests := []struct {
+		where string
+		want  []string
+	}{
+		{"prio DI (RENDAH, LUHUR)", []string{"1", "3"}},
+		{"prio TEU DI (RENDAH, LUHUR)", []string{"2", "4"}},
+		{"nama DI ('Siti', 'Dadang')", []string{"2", "3"}},
+		{"umur ANTARA -5 JEUNG 22", []string{"2", "3"}},
+		{"umur TEU ANTARA 0 JEUNG 25", []string{"1", "3", "4"}},
+		{"prio ANTARA SEDENG JEUNG LUHUR", []string{"2", "3", "4"}},
+		{"masuk ANTARA 2023-01-01 JEUNG 2024-12-31", []string{"1", "2"}},
+		{"nama KOSONG", []string{"4"}},
+		{"masuk KOSONG ATAWA umur KOSONG", []string{"3", "4"}},
+		{"umur TEU KOSONG", []string{"1", "2", "3"}},
+		{"nama COCOG '^[AS]'", []string{"1", "2"}},
+		{"nama TEU COCOG 'g$'", []string{"1", "2", "4"}},
+		{"nama COCOG '(?i)^asep'", []string{"1"}},
+		{"umur > 0 SARENG (prio = SEDENG ATAWA nama JIGA nandar)", []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		got, err := matchIDs(t, tt.where)
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("%s: meunang %v, kuduna %v", tt.where, got, tt.want)
+		}
+	}
+
+	for _, where := range []string{
+		"prio DI (RENDAH, PENTING)",
+		"masuk ANTARA 2023-01-01 JEUNG kamari",
+		"nama COCOG '('",
+		"gaji KOSONG",
+	} {
+		if _, err := matchIDs(t, where); err == nil {
+			t.Errorf("%s: kuduna error", where)
+		}
+	}
+}
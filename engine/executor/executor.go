@@ -4,7 +4,6 @@ import (
 	"errors"
 	"fmt"
 	"sort"
-	"strconv"
 	"strings"
 
 	"github.com/febrd/maungdb/engine/auth"
@@ -17,27 +16,70 @@ type ExecutionResult struct {
 	Columns []string
 	Rows    [][]string
 	Message string
+
+	types []schema.Column // tipe kolom hasil TINGALI (SIMPEN/DAMEL ... TINGALI)
+}
+
+// engine nyaeta panyimpenan baris nu dipake ku executor (default: file).
+var engine storage.Engine = storage.NewFileEngine()
+
+// SetEngine ngaganti engine panyimpenan, contona storage.NewMemoryEngine()
+// pikeun `maung server --memory`.
+func SetEngine(e storage.Engine) {
+	engine = e
 }
 
-func Execute(cmd *parser.Command) (*ExecutionResult, error) {
+// Execute ngajalankeun cmd salaku user. Identitas & database nu dipilih
+// datang ti nu manggil (session CLI atawa session server), lain ti state global.
+// Unggal paréntah langsung di-commit; pikeun MIMITIAN tingali Begin.
+func Execute(cmd *parser.Command, user *auth.User) (*ExecutionResult, error) {
+	switch cmd.Type {
+	case parser.CmdBegin:
+		return nil, errors.New("MIMITIAN ngan bisa dina shell atawa server (handle transaksi)")
+	case parser.CmdCommit, parser.CmdRollback:
+		return nil, errors.New("euweuh transaksi nu keur jalan")
+	}
+	return run(cmd, user, engine)
+}
+
+// run ngajalankeun cmd kana store (engine, atawa transaksi).
+func run(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	if user == nil {
+		return nil, errors.New("can login heula")
+	}
+	if user.Database == "" && !isDatabaseDDL(cmd.Type) {
+		return nil, errors.New("can use database heula")
+	}
+
+	if cmd.Explain {
+		return execExplain(cmd, user, store)
+	}
+
 	switch cmd.Type {
 	case parser.CmdCreate:
-		return execCreate(cmd)
+		if cmd.Source != nil {
+			return execCreateAs(cmd, user, store)
+		}
+		return execCreate(cmd, user)
 	case parser.CmdInsert:
-		return execInsert(cmd)
+		return execInsert(cmd, user, store)
 	case parser.CmdSelect:
-		return execSelect(cmd)
+		return execSelect(cmd, user, store)
 	case parser.CmdUpdate:
-		return execUpdate(cmd)
+		return execUpdate(cmd, user, store)
 	case parser.CmdDelete:
-		return execDelete(cmd)
+		return execDelete(cmd, user, store)
+	case parser.CmdCreateIndex:
+		return execCreateIndex(cmd, user, store)
+	case parser.CmdDropTable, parser.CmdTruncate, parser.CmdRenameTable,
+		parser.CmdDropDatabase, parser.CmdRenameDatabase:
+		return execDDL(cmd, user, store)
 	default:
 		return nil, errors.New("command teu didukung")
 	}
 }
 
-func execCreate(cmd *parser.Command) (*ExecutionResult, error) {
-	user, _ := auth.CurrentUser()
+func execCreate(cmd *parser.Command, user *auth.User) (*ExecutionResult, error) {
 	fields := splitColumns(cmd.Data)
 
 	perms := map[string][]string{
@@ -56,8 +98,21 @@ func splitColumns(input string) []string {
 	var fields []string
 	var currentField strings.Builder
 	parenCount := 0 
+	var quote rune // koma di jero default nu dipetik ('a, b') lain pamisah
 
 	for _, char := range input {
+		switch {
+		case quote != 0:
+			if char == quote {
+				quote = 0
+			}
+			currentField.WriteRune(char)
+			continue
+		case char == '\'' || char == '"':
+			quote = char
+			currentField.WriteRune(char)
+			continue
+		}
 		switch char {
 		case '(':
 			parenCount++
@@ -84,9 +139,7 @@ func splitColumns(input string) []string {
 	return fields
 }
 
-func execInsert(cmd *parser.Command) (*ExecutionResult, error) {
-	user, _ := auth.CurrentUser()
-
+func execInsert(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
 	s, err := schema.Load(user.Database, cmd.Table)
 	if err != nil {
 		return nil, err
@@ -96,214 +149,441 @@ func execInsert(cmd *parser.Command) (*ExecutionResult, error) {
 		return nil, errors.New("teu boga hak nulis")
 	}
 
-	if err := s.ValidateRow(cmd.Data); err != nil {
+	if cmd.Source != nil {
+		if cmd, err = selectRows(cmd, s, user, store); err != nil {
+			return nil, err
+		}
+	}
+
+	rows, err := insertRows(cmd, s)
+	if err != nil {
 		return nil, err
 	}
 
-	if err := storage.Append(cmd.Table, cmd.Data); err != nil {
+	ret, err := compileReturning(cmd, s.Columns)
+	if err != nil {
 		return nil, err
 	}
 
-	return &ExecutionResult{
-		Message: fmt.Sprintf("✅ Data asup ka table '%s'", cmd.Table),
-	}, nil
+	t, err := store.Open(user.Database, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	if cmd.Conflict != nil {
+		return execUpsert(cmd, s, t, rows, ret)
+	}
+
+	if len(rows) > 0 {
+		if err := t.Insert(rows...); err != nil {
+			return nil, err
+		}
+	}
+
+	msg := fmt.Sprintf("✅ Data asup ka table '%s'", cmd.Table)
+	if len(rows) != 1 {
+		msg = fmt.Sprintf("✅ %d data asup ka table '%s'", len(rows), cmd.Table)
+	}
+	return returningResult(ret, rows, msg)
 }
 
-func execSelect(cmd *parser.Command) (*ExecutionResult, error) {
-	user, _ := auth.CurrentUser()
-	s, err := schema.Load(user.Database, cmd.Table)
-	if err != nil { 
-		return nil, err 
+// insertRows nyusun baris lengkep tina SIMPEN: kolom nu teu disebut
+// dieusian default-na, atawa null lamun euweuh default. Sakabéh baris divalidasi heula; lamun aya hiji nu
+// salah, euweuh nu disimpen.
+func insertRows(cmd *parser.Command, s *schema.Definition) ([][]string, error) {
+	pos := make([]int, len(s.Columns))
+	for i := range pos {
+		pos[i] = i
 	}
-	if !s.Can(user.Role, "read") { 
-		return nil, errors.New("teu boga hak maca") 
+	if cmd.Columns != nil {
+		pos = make([]int, len(cmd.Columns))
+		seen := make(map[int]bool)
+		for i, name := range cmd.Columns {
+			p := findColumn(name, s.Columns)
+			if p < 0 {
+				return nil, fmt.Errorf("kolom '%s' teu kapanggih", name)
+			}
+			if seen[p] {
+				return nil, fmt.Errorf("kolom '%s' disebut leuwih ti sakali", name)
+			}
+			seen[p] = true
+			pos[i] = p
+		}
 	}
 
-	rawRows, err := storage.ReadAll(cmd.Table)
-	if err != nil { 
-		return nil, err 
+	rows := make([][]string, len(cmd.Rows))
+	for r, values := range cmd.Rows {
+		var err error
+		if cmd.Columns == nil && len(values) != len(s.Columns) {
+			err = errors.New("jumlah kolom teu sesuai")
+		} else if len(values) != len(pos) {
+			err = fmt.Errorf("aya %d nilai keur %d kolom", len(values), len(pos))
+		}
+
+		row := make([]string, len(s.Columns))
+		null := make([]bool, len(s.Columns))
+		if err == nil {
+			for i, col := range s.Columns {
+				row[i] = col.Default
+				null[i] = col.Default == ""
+			}
+			for i, p := range pos {
+				row[p] = values[i]
+				null[p] = cmd.Nulls != nil && cmd.Nulls[r][i]
+			}
+			err = s.ValidateRow(row, null)
+		}
+		if err != nil {
+			if len(cmd.Rows) > 1 {
+				return nil, fmt.Errorf("baris ka-%d: %w", r+1, err)
+			}
+			return nil, err
+		}
+		rows[r] = row
+	}
+	return rows, nil
+}
+
+func execSelect(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	s, t, err := openSource(cmd, user, store)
+	if err != nil {
+		return nil, err
+	}
+
+	pred, err := compileWhere(cmd.Where, s.Columns)
+	if err != nil {
+		return nil, err
+	}
+
+	agg, err := compileAggregate(cmd, s.Columns)
+	if err != nil {
+		return nil, err
+	}
+	if agg != nil {
+		return execAggregate(cmd, s, t, pred, agg)
+	}
+
+	proj, err := compileProjection(cmd.Fields, s.Columns)
+	if err != nil {
+		return nil, err
 	}
 
+	plan, err := planSelect(cmd, s, t)
+	if err != nil {
+		return nil, err
+	}
+
+	it, err := plan.open(t)
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
 	var parsedRows [][]string
-	fieldNames := s.GetFieldNames()
-
-	for _, raw := range rawRows {
-		if raw == "" { continue }
-		cols := strings.Split(raw, "|")
-
-		if len(cmd.Where) > 0 {
-			matchAll := true
-			currentMatch := evaluateOne(cols, s.Columns, cmd.Where[0])
-			for i := 0; i < len(cmd.Where); i++ {
-				cond := cmd.Where[i]
-				if cond.LogicOp == "" { 
-					matchAll = currentMatch; break 
-				}
-				nextResult := evaluateOne(cols, s.Columns, cmd.Where[i+1])
-				if cond.LogicOp == "SARENG" || cond.LogicOp == "sareng"  { 
-					currentMatch = currentMatch && nextResult 
-				} else if cond.LogicOp == "ATAWA" || cond.LogicOp == "atawa" { 
-					currentMatch = currentMatch || nextResult 
-				}
+
+	// Tanpa sort di memori, LIWATAN & SAKADAR diterapkeun bari maca, jadi
+	// SAKADAR 10 eureun maca sanggeus 10 baris nu cocog.
+	streaming := !plan.sort
+	skipped := 0
+
+	for it.Next() {
+		cols := it.Row()
+
+		if pred != nil {
+			ok, err := pred(cols)
+			if err != nil {
+				return nil, err
+			}
+			if !ok {
+				continue
 			}
-			if !matchAll { continue }
 		}
+
+		if streaming && skipped < cmd.Offset {
+			skipped++
+			continue
+		}
+
 		parsedRows = append(parsedRows, cols)
+
+		if streaming && cmd.Limit > 0 && len(parsedRows) >= cmd.Limit {
+			break
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
 	}
 
-	if cmd.OrderBy != "" {
-		colIdx := indexOf(cmd.OrderBy, fieldNames)
-		if colIdx == -1 { 
-			return nil, fmt.Errorf("kolom '%s' teu kapanggih", cmd.OrderBy) 
+	if streaming {
+		return projectResult(proj, s.Columns, parsedRows)
+	}
+
+	sortRows(parsedRows, plan.order)
+
+	return projectResult(proj, s.Columns, pageRows(parsedRows, cmd.Offset, cmd.Limit))
+}
+
+// orderKey nyaéta hiji kolom RUNTUYKEUN nu geus dibeungkeut.
+type orderKey struct {
+	pos  int
+	col  schema.Column
+	desc bool
+}
+
+func compileOrder(items []parser.OrderItem, cols []schema.Column) ([]orderKey, error) {
+	keys := make([]orderKey, len(items))
+	for i, o := range items {
+		pos, err := lookupColumn(o.Column, cols)
+		if err != nil {
+			return nil, err
 		}
-		
-		colType := s.Columns[colIdx].Type
-
-		sort.Slice(parsedRows, func(i, j int) bool {
-			valA := parsedRows[i][colIdx]
-			valB := parsedRows[j][colIdx]
-			isLess := false
-			
-			switch colType {
-			case "INT":
-				a, _ := strconv.Atoi(valA)
-				b, _ := strconv.Atoi(valB)
-				isLess = a < b
-			case "FLOAT":
-				a, _ := strconv.ParseFloat(valA, 64)
-				b, _ := strconv.ParseFloat(valB, 64)
-				isLess = a < b
-			default: 
-				isLess = valA < valB
-			}
+		if pos < 0 {
+			return nil, fmt.Errorf("kolom '%s' teu kapanggih", o.Column)
+		}
+		keys[i] = orderKey{pos: pos, col: cols[pos], desc: o.Desc}
+	}
+	return keys, nil
+}
 
-			if cmd.OrderDesc {
-				return !isLess 
+// sortRows ngurutkeun baris numutkeun kolom-kolom RUNTUYKEUN (schema.Column
+// Order). Sort-na stabil: baris nu koncina sarua tetep dina urutan asalna.
+func sortRows(rows [][]string, keys []orderKey) {
+	if len(keys) == 0 {
+		return
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		for _, k := range keys {
+			c := k.col.Compare(cell(rows[i], k.pos), cell(rows[j], k.pos))
+			if k.desc {
+				c = -c
+			}
+			if c != 0 {
+				return c < 0
 			}
-			return isLess 
-		})
+		}
+		return false
+	})
+}
+
+func cell(row []string, pos int) string {
+	if pos >= len(row) {
+		return ""
 	}
+	return row[pos]
+}
 
-	totalRows := len(parsedRows)
-	start := 0
-	end := totalRows
+// pageRows nerapkeun LIWATAN jeung SAKADAR ka baris nu geus diurutkeun.
+func pageRows(rows [][]string, offset, limit int) [][]string {
+	start := min(max(offset, 0), len(rows))
+	end := len(rows)
+	if limit > 0 {
+		end = min(start+limit, end)
+	}
+	return rows[start:end]
+}
 
-	if cmd.Offset > 0 {
-		start = cmd.Offset
-		if start > totalRows { start = totalRows }
+// compileReturning ngabeungkeut BALIKKEUN. nil lamun euweuh BALIKKEUN.
+func compileReturning(cmd *parser.Command, cols []schema.Column) (*projection, error) {
+	if cmd.Returning == nil {
+		return nil, nil
 	}
+	return compileProjection(cmd.Returning, cols)
+}
 
-	if cmd.Limit > 0 {
-		end = start + cmd.Limit
-		if end > totalRows { end = totalRows }
+// returningResult: pesen SIMPEN / OMEAN / MICEUN, ditambah baris nu keuna
+// lamun aya BALIKKEUN.
+func returningResult(ret *projection, rows [][]string, msg string) (*ExecutionResult, error) {
+	if ret == nil {
+		return &ExecutionResult{Message: msg}, nil
 	}
+	res, err := projectResult(ret, nil, rows)
+	if err != nil {
+		return nil, err
+	}
+	res.Message = msg
+	return res, nil
+}
 
-	finalRows := parsedRows[start:end]
+// projectResult nerapkeun proyéksi (lamun aya) ka baris hasil TINGALI.
+func projectResult(proj *projection, cols []schema.Column, rows [][]string) (*ExecutionResult, error) {
+	if proj == nil {
+		return &ExecutionResult{Columns: displayNames(cols), Rows: rows, types: cols}, nil
+	}
 
-	return &ExecutionResult{
-		Columns: fieldNames,
-		Rows:    finalRows,
-	}, nil
+	out := make([][]string, len(rows))
+	for i, row := range rows {
+		projected, err := proj.apply(row)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = projected
+	}
+	return &ExecutionResult{Columns: proj.names, Rows: out, types: proj.types()}, nil
 }
 
 
-func execUpdate(cmd *parser.Command) (*ExecutionResult, error) {
-	user, _ := auth.CurrentUser()
+func execUpdate(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
 	s, err := schema.Load(user.Database, cmd.Table)
 	if err != nil { return nil, err }
 	if !s.Can(user.Role, "write") { return nil, errors.New("teu boga hak nulis (omean)") }
+	if err := requireFilter(cmd); err != nil { return nil, err }
 
-	rawRows, err := storage.ReadAll(cmd.Table)
+	t, err := store.Open(user.Database, cmd.Table)
 	if err != nil { return nil, err }
 
-	var newRows []string
-	updatedCount := 0
+	pred, err := compileWhere(cmd.Where, s.Columns)
+	if err != nil { return nil, err }
+
+	set, err := compileAssignments(cmd.Updates, s.Columns)
+	if err != nil { return nil, err }
 
-	for _, raw := range rawRows {
-		if raw == "" { continue }
-		cols := strings.Split(raw, "|")
+	ret, err := compileReturning(cmd, s.Columns)
+	if err != nil { return nil, err }
 
-		shouldUpdate := false
-		if len(cmd.Where) == 0 {
-			shouldUpdate = true 
-		} else {
-			currentMatch := evaluateOne(cols, s.Columns, cmd.Where[0])
-			shouldUpdate = currentMatch
+	shouldUpdate := func(cols []string) (bool, error) {
+		if pred == nil {
+			return true, nil
 		}
+		return pred(cols)
+	}
 
-		if shouldUpdate {
-			for colName, newVal := range cmd.Updates {
-				idx := indexOf(colName, s.GetFieldNames())
-				if idx != -1 {
-					cols[idx] = newVal 
-				}
-			}
-			updatedCount++
+	if pred != nil {
+		hit, err := anyMatch(t, indexConditions(cmd.Where, s.Columns), pred)
+		if err != nil {
+			return nil, err
+		}
+		if !hit {
+			return returningResult(ret, nil, "✅ 0 data geus diomean")
 		}
-		
-		newRows = append(newRows, strings.Join(cols, "|"))
 	}
 
-	if err := storage.Rewrite(cmd.Table, newRows); err != nil {
+	var updated [][]string
+	updatedCount, err := t.Update(func(cols []string) ([]string, bool, error) {
+		ok, err := shouldUpdate(cols)
+		if err != nil || !ok {
+			return nil, false, err
+		}
+
+		row, null, err := set.apply(cols)
+		if err != nil {
+			return nil, false, err
+		}
+		// hiji baris teu valid = sakabéh OMEAN batal (Update teu nulis naon-naon)
+		if err := s.ValidateRow(row, null); err != nil {
+			return nil, false, fmt.Errorf("OMEAN dibatalkeun, euweuh data nu robah: %w", err)
+		}
+		if ret != nil {
+			updated = append(updated, row)
+		}
+		return row, true, nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	return &ExecutionResult{Message: fmt.Sprintf("✅ %d data geus diomean", updatedCount)}, nil
+	return returningResult(ret, updated, fmt.Sprintf("✅ %d data geus diomean", updatedCount))
 }
 
-func execDelete(cmd *parser.Command) (*ExecutionResult, error) {
-	user, _ := auth.CurrentUser()
+// assignments nyaéta JADI <kolom> = <nilai>, ... nu geus dibeungkeut.
+type assignments struct {
+	pos  []int
+	vals []scalar
+}
+
+func compileAssignments(updates []parser.Assignment, cols []schema.Column) (*assignments, error) {
+	set := &assignments{}
+	for _, u := range updates {
+		pos := findColumn(u.Column, cols)
+		if pos < 0 {
+			return nil, fmt.Errorf("kolom '%s' teu kapanggih", u.Column)
+		}
+		val, err := compileOperand(u.Value, cols)
+		if err != nil {
+			return nil, err
+		}
+		set.pos = append(set.pos, pos)
+		set.vals = append(set.vals, val)
+	}
+	return set, nil
+}
+
+// apply mulangkeun baris anyar jeung null-na. Sakabéh nilai diitung tina
+// baris heubeul, jadi "a = b, b = a" silih tukeurkeun. Null nu geus aya
+// tetep null, kitu ogé nilai nu disalin tina kolom null; ari '' nu ditulis
+// langsung dipariksa numutkeun tipe kolom.
+func (set *assignments) apply(row []string) ([]string, []bool, error) {
+	out := append([]string(nil), row...)
+	null := nulls(row)
+	for i, val := range set.vals {
+		v, err := val.eval(row)
+		if err != nil {
+			return nil, nil, err
+		}
+		out[set.pos[i]] = v
+		null[set.pos[i]] = val.col != nil && v == ""
+	}
+	return out, null, nil
+}
+
+func execDelete(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
 	s, err := schema.Load(user.Database, cmd.Table)
 	if err != nil { return nil, err }
 	if !s.Can(user.Role, "write") { return nil, errors.New("teu boga hak nulis (miceun)") }
+	if err := requireFilter(cmd); err != nil { return nil, err }
 
-	rawRows, err := storage.ReadAll(cmd.Table)
+	t, err := store.Open(user.Database, cmd.Table)
 	if err != nil { return nil, err }
 
-	var newRows []string
-	deletedCount := 0
+	pred, err := compileWhere(cmd.Where, s.Columns)
+	if err != nil { return nil, err }
 
-	for _, raw := range rawRows {
-		if raw == "" { continue }
-		cols := strings.Split(raw, "|")
+	ret, err := compileReturning(cmd, s.Columns)
+	if err != nil { return nil, err }
 
-		shouldDelete := false
-		if len(cmd.Where) > 0 {
-			shouldDelete = evaluateOne(cols, s.Columns, cmd.Where[0])
+	var deleted [][]string
+	shouldDelete := func(cols []string) (bool, error) {
+		hit := true
+		if pred != nil {
+			var err error
+			if hit, err = pred(cols); err != nil {
+				return false, err
+			}
 		}
+		if hit && ret != nil {
+			deleted = append(deleted, append([]string(nil), cols...))
+		}
+		return hit, nil
+	}
 
-		if shouldDelete {
-			deletedCount++
-			continue 
+	if pred != nil {
+		hit, err := anyMatch(t, indexConditions(cmd.Where, s.Columns), pred)
+		if err != nil {
+			return nil, err
+		}
+		if !hit {
+			return returningResult(ret, nil, "✅ 0 data geus dipiceun")
 		}
-		
-		newRows = append(newRows, raw)
 	}
 
-	if err := storage.Rewrite(cmd.Table, newRows); err != nil {
+	deletedCount, err := t.Delete(shouldDelete)
+	if err != nil {
 		return nil, err
 	}
 
-	return &ExecutionResult{Message: fmt.Sprintf("✅ %d data geus dipiceun", deletedCount)}, nil
+	return returningResult(ret, deleted, fmt.Sprintf("✅ %d data geus dipiceun", deletedCount))
 }
 
-func evaluateOne(row []string, cols []schema.Column, cond parser.Condition) bool {
-	idx := -1
-	var colType string
-
-	for i, c := range cols {
-		if c.Name == cond.Field {
-			idx = i
-			colType = c.Type
-			break
-		}
+// requireFilter: OMEAN / MICEUN tanpa DIMANA kudu nganggo SADAYANA, supaya
+// sakabéh baris teu kaomean / kapiceun ku teu ngahaja.
+func requireFilter(cmd *parser.Command) error {
+	if cmd.Where != nil || cmd.All {
+		return nil
 	}
-
-	if idx < 0 || idx >= len(row) {
-		return false
+	verb := "OMEAN"
+	if cmd.Type == parser.CmdDelete {
+		verb = "MICEUN"
 	}
-
-	return match(row[idx], cond.Operator, cond.Value, colType)
+	return fmt.Errorf("%s tanpa DIMANA bakal keuna ka sakabéh baris; tambahkeun SADAYANA pikeun mastikeun", verb)
 }
 
 func indexOf(field string, fields []string) int {
@@ -314,65 +594,3 @@ func indexOf(field string, fields []string) int {
 	}
 	return -1
 }
-func match(a, op, b, colType string) bool {
-	if strings.ToUpper(op) == "JIGA" {
-		return strings.Contains(strings.ToLower(a), strings.ToLower(b))
-	}
-
-	switch colType {
-	case "INT":
-		numA, errA := strconv.Atoi(a)
-		numB, errB := strconv.Atoi(b)
-		
-		if errA != nil || errB != nil {
-			return false
-		}
-
-		switch op {
-		case "=":  return numA == numB
-		case "!=": return numA != numB
-		case ">":  return numA > numB
-		case "<":  return numA < numB
-		case ">=": return numA >= numB
-		case "<=": return numA <= numB
-		}
-
-	case "FLOAT":
-		fA, errA := strconv.ParseFloat(a, 64)
-		fB, errB := strconv.ParseFloat(b, 64)
-		
-		if errA != nil || errB != nil {
-			return false
-		}
-
-		switch op {
-		case "=":  return fA == fB
-		case "!=": return fA != fB
-		case ">":  return fA > fB
-		case "<":  return fA < fB
-		case ">=": return fA >= fB
-		case "<=": return fA <= fB
-		}
-
-	case "BOOL":
-		if op == "=" { return a == b }
-		if op == "!=" { return a != b }
-		return false
-
-
-	case "STRING", "TEXT", "CHAR", "ENUM", "DATE":
-		switch op {
-		case "=":  return a == b
-		case "!=": return a != b
-		case ">":  return a > b
-		case "<":  return a < b
-		case ">=": return a >= b
-		case "<=": return a <= b
-		}
-
-	default:
-		return false
-	}
-	
-	return false
-}
\ No newline at end of file
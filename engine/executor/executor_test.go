@@ -0,0 +1,226 @@
+package executor
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+	"github.com/febrd/maungdb/internal/config"
+)
+
+// testDB nyieun maung_data samentara kalawan database "a", sarta mulangkeun
+// user supermaung nu geus use database éta.
+func testDB(t *testing.T) *auth.User {
+	t.Helper()
+	saved := config.DataDir
+	config.DataDir = t.TempDir()
+	t.Cleanup(func() { config.DataDir = saved })
+
+	if err := storage.Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := storage.CreateDatabase("a"); err != nil {
+		t.Fatal(err)
+	}
+	return &auth.User{Username: "maung", Role: "supermaung", Database: "a"}
+}
+
+// exec ngajalankeun hiji query kana store.
+func exec(t *testing.T, user *auth.User, store storage.Engine, query string) (*ExecutionResult, error) {
+	t.Helper()
+	cmd, err := parser.Parse(query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return run(cmd, user, store)
+}
+
+// mustExec: sarua jeung exec, tapi kasalahan ngagagalkeun tés.
+func mustExec(t *testing.T, user *auth.User, store storage.Engine, query string) *ExecutionResult {
+	t.Helper()
+	res, err := exec(t, user, store, query)
+	if err != nil {
+		t.Fatalf("%s: %v", query, err)
+	}
+	return res
+}
+
+// failingEngine: engine nu Insert-na salawasna gagal.
+type failingEngine struct{ storage.Engine }
+
+type failingTable struct{ storage.Table }
+
+func (e failingEngine) Open(database, table string) (storage.Table, error) {
+	t, err := e.Engine.Open(database, table)
+	return failingTable{t}, err
+}
+
+func (failingTable) Insert(rows ...[]string) error { return errors.New("disk pinuh") }
+
+func TestCreateAsCleansUpWhenInsertFails(t *testing.T) {
+	user := testDB(t)
+	store := storage.NewFileEngine()
+	mustExec(t, user, store, "DAMEL peg id:INT,nama:STRING")
+	mustExec(t, user, store, "SIMPEN peg 1|Asep")
+
+	if _, err := exec(t, user, failingEngine{store}, "DAMEL arsip SALAKU TINGALI * TI peg"); err == nil {
+		t.Fatal("kuduna error")
+	}
+	if schema.Exists("a", "arsip") {
+		t.Fatal("schema tabel nu gagal didamel masih aya")
+	}
+
+	res := mustExec(t, user, store, "DAMEL arsip SALAKU TINGALI * TI peg")
+	if res.Message == "" {
+		t.Fatal("euweuh pesen")
+	}
+	if got := mustExec(t, user, store, "TINGALI arsip"); len(got.Rows) != 1 {
+		t.Fatalf("arsip: %v", got.Rows)
+	}
+}
+
+func TestIndexOrderMatchesSort(t *testing.T) {
+	t.Run("file", func(t *testing.T) { testIndexOrder(t, storage.NewFileEngine()) })
+	t.Run("memory", func(t *testing.T) { testIndexOrder(t, storage.NewMemoryEngine()) })
+}
+
+func testIndexOrder(t *testing.T, store storage.Engine) {
+	user := testDB(t)
+
+	// dua tabel eusina sarua, ngan hiji nu boga indeks
+	for _, table := range []string{"polos", "ix"} {
+		mustExec(t, user, store, "DAMEL "+table+" id:INT,prio:ENUM(RENDAH,SEDENG,LUHUR),masuk:DATE,aktif:BOOL,n:INT")
+		mustExec(t, user, store, "SIMPEN "+table+" NILAI "+
+			"(1, SEDENG, 2024-01-10, true, 5), (2, RENDAH, KOSONG, false, 5), (3, LUHUR, 2023-05-01, KOSONG, 1), "+
+			"(4, SEDENG, 2024-01-10, true, 5), (5, KOSONG, 2022-12-31, false, 3), (6, RENDAH, KOSONG, true, 1)")
+	}
+	for _, col := range []string{"prio", "masuk", "aktif", "n"} {
+		mustExec(t, user, store, "DAMEL INDEKS ix_"+col+" DINA ix("+col+")")
+	}
+
+	queries := []string{
+		"RUNTUYKEUN prio SAKADAR 4",
+		"RUNTUYKEUN prio TURUN SAKADAR 4",
+		"RUNTUYKEUN masuk SAKADAR 3",
+		"RUNTUYKEUN masuk TURUN SAKADAR 3",
+		"RUNTUYKEUN aktif TURUN SAKADAR 3",
+		"RUNTUYKEUN n TURUN SAKADAR 2",
+		"DIMANA prio < LUHUR RUNTUYKEUN prio TURUN SAKADAR 3",
+		"DIMANA n >= 3 RUNTUYKEUN masuk SAKADAR 2",
+		"DIMANA n >= 3 SAKADAR 2",
+		"DIMANA masuk ANTARA 2023-01-01 JEUNG 2024-12-31 RUNTUYKEUN aktif",
+	}
+	for _, q := range queries {
+		want := mustExec(t, user, store, "TINGALI id TI polos "+q)
+		got := mustExec(t, user, store, "TINGALI id TI ix "+q)
+		if !slices.EqualFunc(got.Rows, want.Rows, slices.Equal) {
+			t.Errorf("%s: kalawan indeks %v, tanpa indeks %v", q, got.Rows, want.Rows)
+		}
+	}
+
+	plan := mustExec(t, user, store, "JELASKEUN TINGALI id TI ix RUNTUYKEUN prio TURUN SAKADAR 4")
+	if !slices.ContainsFunc(plan.Rows, func(r []string) bool { return r[1] == "INDEX SCAN" }) {
+		t.Errorf("RUNTUYKEUN ENUM kuduna maké indeks: %v", plan.Rows)
+	}
+}
+
+func TestInsertNulls(t *testing.T) {
+	user := testDB(t)
+	store := storage.NewFileEngine()
+	mustExec(t, user, store, "DAMEL peg id:INT,nama:STRING,umur:INT,status:ENUM(AKTIF,CUTI)=AKTIF")
+
+	for _, q := range []string{
+		"SIMPEN peg (id, nama) NILAI (1, 'Asep')",
+		"SIMPEN peg NILAI (2, 'Siti', KOSONG, CUTI)",
+		"SIMPEN peg NILAI (3, '', 30, KOSONG)",
+		"OMEAN peg JADI nama = 'Asep Sunandar' DIMANA id = 1",
+		"DAMEL arsip SALAKU TINGALI * TI peg",
+		"SIMPEN arsip TINGALI * TI peg DIMANA umur KOSONG",
+	} {
+		mustExec(t, user, store, q)
+	}
+	res := mustExec(t, user, store, "TINGALI peg")
+	want := [][]string{{"1", "Asep Sunandar", "", "AKTIF"}, {"2", "Siti", "", "CUTI"}, {"3", "", "30", ""}}
+	if !slices.EqualFunc(res.Rows, want, slices.Equal) {
+		t.Errorf("peg: %q", res.Rows)
+	}
+
+	// '' lain null: dipariksa numutkeun tipe kolom
+	for _, q := range []string{
+		"SIMPEN peg NILAI (4, 'Dadang', '', AKTIF)",
+		"SIMPEN peg (id, status) NILAI (4, '')",
+		"SIMPEN peg 4|Dadang||AKTIF",
+		"OMEAN peg JADI umur = '' DIMANA id = 3",
+	} {
+		if _, err := exec(t, user, store, q); err == nil {
+			t.Errorf("%s: kuduna error", q)
+		}
+	}
+}
+
+func TestInsertDefaults(t *testing.T) {
+	user := testDB(t)
+	store := storage.NewFileEngine()
+	mustExec(t, user, store, "DAMEL peg id:INT,nama:STRING='teu dipikanyaho',status:ENUM(AKTIF,CUTI)=AKTIF,masuk:DATE=2024-01-01")
+
+	mustExec(t, user, store, "SIMPEN peg (id) NILAI (1), (2)")
+	mustExec(t, user, store, "SIMPEN peg (status, id) NILAI (CUTI, 3)")
+	mustExec(t, user, store, "SIMPEN peg NILAI (4, 'Asep', CUTI, 2025-06-30)")
+	res := mustExec(t, user, store, "TINGALI peg")
+	want := [][]string{
+		{"1", "teu dipikanyaho", "AKTIF", "2024-01-01"},
+		{"2", "teu dipikanyaho", "AKTIF", "2024-01-01"},
+		{"3", "teu dipikanyaho", "CUTI", "2024-01-01"},
+		{"4", "Asep", "CUTI", "2025-06-30"},
+	}
+	if !slices.EqualFunc(res.Rows, want, slices.Equal) {
+		t.Errorf("peg: %q", res.Rows)
+	}
+
+	for _, q := range []string{
+		"DAMEL salah id:INT=abc",
+		"DAMEL salah status:ENUM(A,B)=C",
+		"SIMPEN peg (id, gaji) NILAI (5, 100)",
+		"SIMPEN peg (id, id) NILAI (5, 6)",
+		"SIMPEN peg (id, nama) NILAI (5)",
+		// hiji baris salah = sakabéh SIMPEN batal
+		"SIMPEN peg (id, status) NILAI (5, AKTIF), (6, PENSIUN)",
+	} {
+		if _, err := exec(t, user, store, q); err == nil {
+			t.Errorf("%s: kuduna error", q)
+		}
+	}
+	if res := mustExec(t, user, store, "TINGALI ITUNG(*) TI peg"); res.Rows[0][0] != "4" {
+		t.Errorf("SIMPEN nu gagal nulis baris: %v", res.Rows)
+	}
+}
+
+func TestUpdateDeleteRequireFilter(t *testing.T) {
+	user := testDB(t)
+	store := storage.NewFileEngine()
+	mustExec(t, user, store, "DAMEL peg id:INT,umur:INT")
+	mustExec(t, user, store, "SIMPEN peg NILAI (1, 20), (2, 30)")
+
+	for _, q := range []string{"OMEAN peg JADI umur = 0", "MICEUN TI peg"} {
+		if _, err := exec(t, user, store, q); err == nil || !strings.Contains(err.Error(), "SADAYANA") {
+			t.Errorf("%s: kuduna nolak tanpa SADAYANA, meunang %v", q, err)
+		}
+	}
+	if res := mustExec(t, user, store, "TINGALI ITUNG(*) TI peg DIMANA umur > 0"); res.Rows[0][0] != "2" {
+		t.Fatalf("data robah padahal ditolak: %v", res.Rows)
+	}
+
+	mustExec(t, user, store, "OMEAN peg JADI umur = umur + 1 SADAYANA")
+	if res := mustExec(t, user, store, "TINGALI umur TI peg"); !slices.EqualFunc(res.Rows, [][]string{{"21"}, {"31"}}, slices.Equal) {
+		t.Errorf("OMEAN SADAYANA: %v", res.Rows)
+	}
+	mustExec(t, user, store, "MICEUN TI peg SADAYANA")
+	if res := mustExec(t, user, store, "TINGALI ITUNG(*) TI peg"); res.Rows[0][0] != "0" {
+		t.Errorf("MICEUN SADAYANA: %v", res.Rows)
+	}
+}
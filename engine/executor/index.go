@@ -0,0 +1,73 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// INDEKS
+// =======================
+
+func execCreateIndex(cmd *parser.Command, user *auth.User, store storage.Engine) (*ExecutionResult, error) {
+	s, err := schema.Load(user.Database, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+	if !s.Can(user.Role, "write") {
+		return nil, errors.New("teu boga hak nulis (indeks)")
+	}
+
+	pos := indexOf(cmd.Column, s.GetFieldNames())
+	if pos == -1 {
+		return nil, fmt.Errorf("kolom '%s' teu kapanggih", cmd.Column)
+	}
+
+	t, err := store.Open(user.Database, cmd.Table)
+	if err != nil {
+		return nil, err
+	}
+
+	info := storage.IndexInfo{Name: cmd.Index, Column: s.Columns[pos], Pos: pos}
+	if err := t.CreateIndex(info); err != nil {
+		return nil, err
+	}
+
+	return &ExecutionResult{
+		Message: fmt.Sprintf("✅ Indeks '%s' dina %s(%s) parantos didamel!", cmd.Index, cmd.Table, cmd.Column),
+	}, nil
+}
+
+// anyMatch mariksa ngaliwatan indeks naha aya baris nu cocog jeung pred,
+// supaya OMEAN / MICEUN nu teu keuna ka baris mana-mana teu nulis ulang
+// tabel. Tanpa indeks nu cocog, mulangkeun true.
+func anyMatch(t storage.Table, where []condition, pred predicate) (bool, error) {
+	infos, err := t.Indexes()
+	if err != nil {
+		return false, err
+	}
+
+	c := chooseIndex(infos, where)
+	if c == nil {
+		return true, nil
+	}
+
+	it, err := t.IndexScan(c.info.Name, c.keys)
+	if err != nil {
+		return false, err
+	}
+	defer it.Close()
+
+	for it.Next() {
+		ok, err := pred(it.Row())
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, it.Err()
+}
@@ -0,0 +1,374 @@
+package executor
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// GABUNG (JOIN)
+// =======================
+//
+//	TINGALI m.nama, n.skor TI mahasiswa SALAKU m GABUNG nilai SALAKU n DINA m.id = n.mhs_id
+//
+// Hasil GABUNG dijieun jadi tabel "virtual" (joinedTable) nu kolomna
+// dikualifikasi ku ngaran tabel atawa alias (m.id, n.mhs_id, ...), jadi
+// DIMANA, agrégat, RUNTUYKEUN jeung daptar kolom TINGALI jalan saperti biasa.
+//
+// Babandingan = antara kolom tabel kénca jeung kolom tabel nu digabung
+// dipaké pikeun hash join: baris tabel katuhu dimuat ka memori dikelompokkeun
+// dumasar nilai kolomna. Lamun euweuh babandingan =, dipaké nested loop.
+// GABUNG KENCA (left join) tetep mintonkeun baris kénca nu teu boga pasangan,
+// kolom katuhuna kosong.
+
+// openSource muka tabel TINGALI, kaasup tabel-tabel GABUNG.
+func openSource(cmd *parser.Command, user *auth.User, store storage.Engine) (*schema.Definition, storage.Table, error) {
+	s, t, err := openForRead(cmd, user, store)
+	if err != nil || len(cmd.Joins) == 0 {
+		return s, t, err
+	}
+
+	name := cmd.Table
+	if cmd.TableAlias != "" {
+		name = cmd.TableAlias
+	}
+	names := map[string]bool{name: true}
+	cols := qualifyColumns(name, s.Columns)
+	jt := &joinedTable{base: t, name: describeSource(cmd.Table, cmd.TableAlias)}
+
+	for _, j := range cmd.Joins {
+		if names[j.Name()] {
+			return nil, nil, fmt.Errorf("tabel '%s' geus aya dina query, paké SALAKU pikeun alias", j.Name())
+		}
+		names[j.Name()] = true
+
+		js, err := schema.Load(user.Database, j.Table)
+		if err != nil {
+			return nil, nil, err
+		}
+		if !js.Can(user.Role, "read") {
+			return nil, nil, fmt.Errorf("teu boga hak maca tabel '%s'", j.Table)
+		}
+		right, err := store.Open(user.Database, j.Table)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		step, err := compileJoin(j, right, cols, qualifyColumns(j.Name(), js.Columns))
+		if err != nil {
+			return nil, nil, err
+		}
+		jt.steps = append(jt.steps, step)
+		cols = append(cols, qualifyColumns(j.Name(), js.Columns)...)
+	}
+
+	return &schema.Definition{Columns: cols, Perms: s.Perms}, jt, nil
+}
+
+func qualifyColumns(name string, cols []schema.Column) []schema.Column {
+	out := make([]schema.Column, len(cols))
+	for i, c := range cols {
+		c.Name = name + "." + c.Name
+		out[i] = c
+	}
+	return out
+}
+
+func describeSource(table, alias string) string {
+	if alias != "" {
+		return table + " SALAKU " + alias
+	}
+	return table
+}
+
+// joinStep nyaéta hiji GABUNG nu geus dibeungkeut ka kolom.
+type joinStep struct {
+	join  parser.Join
+	table storage.Table
+	left  int // jumlah kolom kénca
+	right int // jumlah kolom tabel nu digabung
+
+	leftKeys  []scalar // dievaluasi kana baris kénca
+	rightKeys []scalar // dievaluasi kana baris katuhu
+	residual  predicate
+}
+
+// compileJoin misahkeun DINA jadi konci hash join (kolom kénca = kolom
+// katuhu, disambung ku SARENG) jeung saringan sésana.
+func compileJoin(j parser.Join, t storage.Table, left, right []schema.Column) (*joinStep, error) {
+	all := append(append([]schema.Column(nil), left...), right...)
+	step := &joinStep{join: j, table: t, left: len(left), right: len(right)}
+
+	var rest parser.Expr
+	for _, c := range conjuncts(j.On) {
+		l, r, ok, err := joinKey(c, all, len(left))
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			if rest == nil {
+				rest = c
+			} else {
+				rest = &parser.Binary{Op: "SARENG", Left: rest, Right: c}
+			}
+			continue
+		}
+
+		lk, err := compileScalar(&parser.Ident{Name: all[l].Name}, left)
+		if err != nil {
+			return nil, err
+		}
+		rk, err := compileScalar(&parser.Ident{Name: all[r].Name}, right)
+		if err != nil {
+			return nil, err
+		}
+		// dua kolom konci dibandingkeun numutkeun tipe kolom kénca
+		rk.typ = lk.typ
+		step.leftKeys = append(step.leftKeys, lk)
+		step.rightKeys = append(step.rightKeys, rk)
+	}
+
+	residual, err := compileWhere(rest, all)
+	if err != nil {
+		return nil, err
+	}
+	step.residual = residual
+	return step, nil
+}
+
+// conjuncts mulangkeun bagian-bagian e nu disambung ku SARENG.
+func conjuncts(e parser.Expr) []parser.Expr {
+	if b, ok := e.(*parser.Binary); ok && b.Op == "SARENG" {
+		return append(conjuncts(b.Left), conjuncts(b.Right)...)
+	}
+	return []parser.Expr{e}
+}
+
+// joinKey: lamun e wangunna <kolom kénca> = <kolom katuhu> (atawa
+// sabalikna), mulangkeun posisi dua kolomna dina baris gabungan.
+func joinKey(e parser.Expr, cols []schema.Column, split int) (int, int, bool, error) {
+	b, ok := e.(*parser.Binary)
+	if !ok || b.Op != "=" {
+		return 0, 0, false, nil
+	}
+	x, okX := b.Left.(*parser.Ident)
+	y, okY := b.Right.(*parser.Ident)
+	if !okX || !okY {
+		return 0, 0, false, nil
+	}
+
+	l, err := lookupColumn(x.Name, cols)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	r, err := lookupColumn(y.Name, cols)
+	if err != nil {
+		return 0, 0, false, err
+	}
+	switch {
+	case l < 0 || r < 0:
+		return 0, 0, false, nil
+	case l < split && r >= split:
+		return l, r, true, nil
+	case r < split && l >= split:
+		return r, l, true, nil
+	}
+	return 0, 0, false, nil
+}
+
+// joinKeyOf ngahijikeun nilai konci jadi hiji string. false lamun aya nilai nu
+// kosong (kosong teu pernah cocog jeung naon-naon).
+func joinKeyOf(keys []scalar, row []string) (string, bool, error) {
+	parts := make([]string, len(keys))
+	for i, k := range keys {
+		v, err := k.eval(row)
+		if err != nil {
+			return "", false, err
+		}
+		if v == "" {
+			return "", false, nil
+		}
+		parts[i] = canonicalValue(v, k.typ)
+	}
+	return strings.Join(parts, "\x00"), true, nil
+}
+
+func (st *joinStep) hash() bool {
+	return len(st.leftKeys) > 0
+}
+
+// describe: katerangan GABUNG pikeun JELASKEUN.
+func (st *joinStep) describe() (string, string) {
+	op := "NESTED LOOP"
+	if st.hash() {
+		op = "HASH JOIN"
+	}
+	if st.join.Left {
+		op += " KENCA"
+	}
+	return op, fmt.Sprintf("%s DINA %s", describeSource(st.join.Table, st.join.Alias), st.join.On)
+}
+
+// =======================
+// JOINED TABLE
+// =======================
+
+// joinedTable nyaéta hasil GABUNG. Ngan bisa dibaca (Scan); euweuh indeks.
+type joinedTable struct {
+	base  storage.Table
+	name  string
+	steps []*joinStep
+}
+
+var errJoinReadOnly = errors.New("hasil GABUNG ngan bisa dibaca")
+
+func (jt *joinedTable) Scan() (storage.RowIterator, error) {
+	it, err := jt.base.Scan()
+	if err != nil {
+		return nil, err
+	}
+	for _, st := range jt.steps {
+		next, err := st.open(it)
+		if err != nil {
+			it.Close()
+			return nil, err
+		}
+		it = next
+	}
+	return it, nil
+}
+
+func (jt *joinedTable) Insert(...[]string) error               { return errJoinReadOnly }
+func (jt *joinedTable) Update(storage.UpdateFunc) (int, error) { return 0, errJoinReadOnly }
+func (jt *joinedTable) Delete(storage.MatchFunc) (int, error)  { return 0, errJoinReadOnly }
+func (jt *joinedTable) CreateIndex(storage.IndexInfo) error    { return errJoinReadOnly }
+func (jt *joinedTable) Indexes() ([]storage.IndexInfo, error)  { return nil, nil }
+func (jt *joinedTable) Version() (string, error)               { return "", nil }
+func (jt *joinedTable) IndexScan(string, storage.KeyRange) (storage.RowIterator, error) {
+	return nil, errJoinReadOnly
+}
+func (jt *joinedTable) Upsert(storage.UpdateFunc, storage.RestFunc) (int, error) {
+	return 0, errJoinReadOnly
+}
+
+// open maca sakabéh baris tabel katuhu ka memori (dikelompokkeun dumasar
+// konci lamun hash join), tuluy mulangkeun iterator hasil gabungan.
+func (st *joinStep) open(left storage.RowIterator) (storage.RowIterator, error) {
+	it, err := st.table.Scan()
+	if err != nil {
+		return nil, err
+	}
+	defer it.Close()
+
+	ji := &joinIterator{step: st, left: left}
+	if st.hash() {
+		ji.buckets = make(map[string][][]string)
+	}
+	for it.Next() {
+		row := it.Row()
+		if !st.hash() {
+			ji.rows = append(ji.rows, row)
+			continue
+		}
+		key, ok, err := joinKeyOf(st.rightKeys, row)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			ji.buckets[key] = append(ji.buckets[key], row)
+		}
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	return ji, nil
+}
+
+type joinIterator struct {
+	step *joinStep
+	left storage.RowIterator
+
+	rows    [][]string            // nested loop
+	buckets map[string][][]string // hash join
+
+	pending [][]string
+	row     []string
+	err     error
+}
+
+func (ji *joinIterator) Next() bool {
+	for len(ji.pending) == 0 {
+		if ji.err != nil || !ji.left.Next() {
+			return false
+		}
+		if err := ji.match(ji.left.Row()); err != nil {
+			ji.err = err
+			return false
+		}
+	}
+	ji.row, ji.pending = ji.pending[0], ji.pending[1:]
+	return true
+}
+
+// match ngumpulkeun baris gabungan pikeun hiji baris kénca ka pending.
+func (ji *joinIterator) match(left []string) error {
+	st := ji.step
+	candidates := ji.rows
+	if st.hash() {
+		key, ok, err := joinKeyOf(st.leftKeys, left)
+		if err != nil {
+			return err
+		}
+		candidates = nil
+		if ok {
+			candidates = ji.buckets[key]
+		}
+	}
+
+	found := false
+	for _, right := range candidates {
+		row := combineRows(left, st.left, right, st.right)
+		if st.residual != nil {
+			ok, err := st.residual(row)
+			if err != nil {
+				return err
+			}
+			if !ok {
+				continue
+			}
+		}
+		ji.pending = append(ji.pending, row)
+		found = true
+	}
+
+	if !found && st.join.Left {
+		ji.pending = append(ji.pending, combineRows(left, st.left, nil, st.right))
+	}
+	return nil
+}
+
+// combineRows nyambungkeun baris kénca jeung katuhu; kolom nu kurang
+// dieusian kosong.
+func combineRows(left []string, nl int, right []string, nr int) []string {
+	row := make([]string, nl+nr)
+	copy(row[:nl], left)
+	copy(row[nl:], right)
+	return row
+}
+
+func (ji *joinIterator) Row() []string { return ji.row }
+
+func (ji *joinIterator) Err() error {
+	if ji.err != nil {
+		return ji.err
+	}
+	return ji.left.Err()
+}
+
+func (ji *joinIterator) Close() error { return ji.left.Close() }
@@ -0,0 +1,316 @@
+package executor
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// TRANSAKSI
+// =======================
+
+// Transaction ngumpulkeun SIMPEN / OMEAN / MICEUN dina memori nepi ka
+// ANGGEUSAN, jadi sesi séjén teu ningali parobahanana saméméh di-commit.
+// TINGALI di jero transaksi ningali data nu geus di-commit ditambah
+// parobahanana sorangan. Basa ANGGEUSAN, lamun tabel nu kungsi dibaca geus
+// dirobah ku sesi séjén, transaksi ditolak (storage.ErrConflict).
+type Transaction struct {
+	ID string
+
+	mu     sync.Mutex
+	tables map[string]*txTable
+	done   bool
+}
+
+// Begin ngamimitian transaksi anyar (MIMITIAN).
+func Begin() (*Transaction, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	return &Transaction{
+		ID:     hex.EncodeToString(buf),
+		tables: make(map[string]*txTable),
+	}, nil
+}
+
+// Execute ngajalankeun cmd di jero transaksi. ANGGEUSAN / BATALKEUN
+// ngaréngsékeun transaksi (tingali Done).
+func (tx *Transaction) Execute(cmd *parser.Command, user *auth.User) (*ExecutionResult, error) {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+
+	if tx.done {
+		return nil, errors.New("transaksi geus réngsé")
+	}
+
+	switch cmd.Type {
+	case parser.CmdBegin:
+		return nil, errors.New("transaksi geus dimimitian (ANGGEUSAN atawa BATALKEUN heula)")
+	case parser.CmdCommit:
+		n, err := tx.commitLocked()
+		if err != nil {
+			return nil, err
+		}
+		return &ExecutionResult{Message: fmt.Sprintf("✅ Transaksi anggeus (%d tabel dirobah)", n)}, nil
+	case parser.CmdRollback:
+		tx.rollbackLocked()
+		return &ExecutionResult{Message: "✅ Transaksi dibatalkeun"}, nil
+	case parser.CmdCreate, parser.CmdCreateIndex:
+		if !cmd.Explain {
+			return nil, errors.New("DAMEL teu bisa di jero transaksi")
+		}
+	case parser.CmdDropTable, parser.CmdDropDatabase, parser.CmdRenameTable, parser.CmdRenameDatabase:
+		if !cmd.Explain {
+			return nil, errors.New("PICEUN / GANTI NGARAN teu bisa di jero transaksi")
+		}
+	}
+
+	return run(cmd, user, txStore{tx})
+}
+
+// Done: true sanggeus ANGGEUSAN (hasil naon waé) atawa BATALKEUN.
+func (tx *Transaction) Done() bool {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	return tx.done
+}
+
+// Rollback miceun sakabéh parobahan nu can di-commit (contona basa sesi
+// ditutup).
+func (tx *Transaction) Rollback() {
+	tx.mu.Lock()
+	defer tx.mu.Unlock()
+	tx.rollbackLocked()
+}
+
+func (tx *Transaction) rollbackLocked() {
+	tx.tables = nil
+	tx.done = true
+}
+
+// commitLocked nerapkeun parobahan sakabéh tabel dina hiji Engine.Commit.
+// Transaksi réngsé sanajan commit gagal.
+func (tx *Transaction) commitLocked() (int, error) {
+	tx.done = true
+
+	keys := make([]string, 0, len(tx.tables))
+	for key := range tx.tables {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var writes []storage.TableWrites
+	changed := 0
+	for _, key := range keys {
+		t := tx.tables[key]
+		if !t.read && len(t.ops) == 0 {
+			continue
+		}
+
+		w := storage.TableWrites{Database: t.database, Table: t.table, Ops: t.ops}
+		if t.read {
+			w.Version = t.version
+		}
+		writes = append(writes, w)
+		if len(t.ops) > 0 {
+			changed++
+		}
+	}
+	tx.tables = nil
+
+	if len(writes) == 0 {
+		return 0, nil
+	}
+	if err := engine.Commit(writes); err != nil {
+		return 0, err
+	}
+	return changed, nil
+}
+
+// txStore nyaeta storage.Engine nu dipaké ku executor di jero transaksi.
+type txStore struct {
+	tx *Transaction
+}
+
+func (s txStore) Open(database, table string) (storage.Table, error) {
+	key := database + "/" + table
+	if t, ok := s.tx.tables[key]; ok {
+		return t, nil
+	}
+
+	base, err := engine.Open(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	t := &txTable{database: database, table: table, base: base}
+	s.tx.tables[key] = t
+	return t, nil
+}
+
+func (s txStore) Drop(database, table string) error {
+	return errors.New("teu bisa miceun tabel di jero transaksi")
+}
+
+func (s txStore) Rename(database, table, newDatabase, newTable string) error {
+	return errors.New("teu bisa ngaganti ngaran tabel di jero transaksi")
+}
+
+func (s txStore) RenameDatabase(database, newDatabase string) error {
+	return errors.New("teu bisa ngaganti ngaran database di jero transaksi")
+}
+
+func (s txStore) Commit(writes []storage.TableWrites) error {
+	return errors.New("transaksi teu bisa disarangkeun")
+}
+
+// txTable nyaeta tabel ditempo ti jero transaksi: data nu geus di-commit
+// (base) + ops nu can di-commit.
+type txTable struct {
+	database string
+	table    string
+	base     storage.Table
+	ops      []storage.WriteOp
+
+	// versi base basa mimiti dibaca; dipariksa deui basa ANGGEUSAN
+	read    bool
+	version string
+}
+
+// pending: aya parobahan nu can di-commit (indeks base teu bisa dipaké).
+func (t *txTable) pending() bool { return len(t.ops) > 0 }
+
+func (t *txTable) observe() error {
+	if t.read {
+		return nil
+	}
+	v, err := t.base.Version()
+	if err != nil {
+		return err
+	}
+	t.read, t.version = true, v
+	return nil
+}
+
+func (t *txTable) Scan() (storage.RowIterator, error) {
+	if err := t.observe(); err != nil {
+		return nil, err
+	}
+	it, err := t.base.Scan()
+	if err != nil || !t.pending() {
+		return it, err
+	}
+	return storage.ApplyWrites(it, t.ops), nil
+}
+
+func (t *txTable) Insert(rows ...[]string) error {
+	copied := make([][]string, len(rows))
+	for i, row := range rows {
+		copied[i] = append([]string(nil), row...)
+	}
+	t.ops = append(t.ops, storage.WriteOp{Insert: copied})
+	return nil
+}
+
+// Update ngitung baris nu keuna (tina data transaksi ayeuna), tuluy nyimpen
+// fn pikeun diterapkeun basa ANGGEUSAN.
+func (t *txTable) Update(fn storage.UpdateFunc) (int, error) {
+	count, err := t.count(func(row []string) (bool, error) {
+		_, ok, err := fn(row)
+		return ok, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	t.ops = append(t.ops, storage.WriteOp{Update: fn})
+	return count, nil
+}
+
+// Upsert: baris nu keuna jeung baris tambahan diitung tina data transaksi
+// ayeuna. Tabelna geus dibaca (observe), jadi lamun sesi séjén nulis
+// saméméh ANGGEUSAN, transaksi ditolak.
+func (t *txTable) Upsert(fn storage.UpdateFunc, rest storage.RestFunc) (int, error) {
+	count, err := t.count(func(row []string) (bool, error) {
+		_, ok, err := fn(row)
+		return ok, err
+	})
+	if err != nil {
+		return 0, err
+	}
+	rows, err := rest()
+	if err != nil {
+		return 0, err
+	}
+
+	if count > 0 {
+		t.ops = append(t.ops, storage.WriteOp{Update: fn})
+	}
+	if len(rows) > 0 {
+		if err := t.Insert(rows...); err != nil {
+			return 0, err
+		}
+	}
+	return count, nil
+}
+
+func (t *txTable) Delete(fn storage.MatchFunc) (int, error) {
+	count, err := t.count(fn)
+	if err != nil {
+		return 0, err
+	}
+	t.ops = append(t.ops, storage.WriteOp{Delete: fn})
+	return count, nil
+}
+
+func (t *txTable) count(fn storage.MatchFunc) (int, error) {
+	it, err := t.Scan()
+	if err != nil {
+		return 0, err
+	}
+	defer it.Close()
+
+	count := 0
+	for it.Next() {
+		hit, err := fn(it.Row())
+		if err != nil {
+			return 0, err
+		}
+		if hit {
+			count++
+		}
+	}
+	return count, it.Err()
+}
+
+func (t *txTable) CreateIndex(info storage.IndexInfo) error {
+	return errors.New("DAMEL teu bisa di jero transaksi")
+}
+
+// Indexes: indeks base ngan gambaran data nu geus di-commit, jadi teu
+// dipaké lamun aya parobahan nu can di-commit.
+func (t *txTable) Indexes() ([]storage.IndexInfo, error) {
+	if t.pending() {
+		return nil, nil
+	}
+	return t.base.Indexes()
+}
+
+func (t *txTable) IndexScan(name string, r storage.KeyRange) (storage.RowIterator, error) {
+	if err := t.observe(); err != nil {
+		return nil, err
+	}
+	return t.base.IndexScan(name, r)
+}
+
+func (t *txTable) Version() (string, error) {
+	return t.base.Version()
+}
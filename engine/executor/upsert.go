@@ -0,0 +1,164 @@
+package executor
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/febrd/maungdb/engine/parser"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/engine/storage"
+)
+
+// =======================
+// SIMPEN ... ATAWA OMEAN (UPSERT)
+// =======================
+//
+//	SIMPEN stok (kode, nama, jumlah) NILAI (A1, 'Baud', 10)
+//	    ATAWA OMEAN DUMASAR kode JADI jumlah
+//
+// Unggal baris anyar dibandingkeun jeung baris nu geus aya dumasar kolom
+// konci (DUMASAR). Lamun koncina geus aya, baris heubeul diomean: ngan kolom
+// JADI nu diganti (default: sakabéh kolom nu disebut dina SIMPEN iwal
+// konci). Lamun can aya, barisna disimpen. Sakabéhna ngaliwatan
+// Table.Upsert, jadi dina hiji konci tabel: dua klien nu ngirim konci nu
+// sarua babarengan moal nyieun dua baris.
+//
+// Konci nu kosong teu pernah cocog jeung naon-naon (barisna salawasna
+// disimpen). Kolom konci teu kudu unik dina tabel; lamun aya sababaraha
+// baris heubeul nu koncina sarua, kabéhanana diomean.
+
+// upsertPlan nyaéta ATAWA OMEAN nu geus dibeungkeut ka kolom.
+type upsertPlan struct {
+	keys      []int // posisi kolom konci
+	overwrite []int // posisi kolom nu diganti basa konci geus aya
+	cols      []schema.Column
+}
+
+func compileUpsert(cmd *parser.Command, s *schema.Definition) (*upsertPlan, error) {
+	// kolom nu dieusian ku SIMPEN (nil = sadaya kolom)
+	listed := make(map[int]bool)
+	for i := range s.Columns {
+		listed[i] = cmd.Columns == nil
+	}
+	for _, name := range cmd.Columns {
+		listed[findColumn(name, s.Columns)] = true
+	}
+
+	p := &upsertPlan{cols: s.Columns}
+	isKey := make(map[int]bool)
+	for _, name := range cmd.Conflict {
+		pos := findColumn(name, s.Columns)
+		switch {
+		case pos < 0:
+			return nil, fmt.Errorf("kolom '%s' teu kapanggih", name)
+		case !listed[pos]:
+			return nil, fmt.Errorf("kolom konci '%s' kudu aya dina daptar kolom SIMPEN", name)
+		case isKey[pos]:
+			return nil, fmt.Errorf("kolom konci '%s' disebut leuwih ti sakali", name)
+		}
+		isKey[pos] = true
+		p.keys = append(p.keys, pos)
+	}
+
+	if cmd.Overwrite == nil {
+		for i := range s.Columns {
+			if listed[i] && !isKey[i] {
+				p.overwrite = append(p.overwrite, i)
+			}
+		}
+		return p, nil
+	}
+
+	seen := make(map[int]bool)
+	for _, name := range cmd.Overwrite {
+		pos := findColumn(name, s.Columns)
+		switch {
+		case pos < 0:
+			return nil, fmt.Errorf("kolom '%s' teu kapanggih", name)
+		case isKey[pos]:
+			return nil, fmt.Errorf("kolom konci '%s' teu bisa dipaké dina JADI", name)
+		case !listed[pos]:
+			return nil, fmt.Errorf("kolom '%s' teu aya dina daptar kolom SIMPEN", name)
+		case seen[pos]:
+			return nil, fmt.Errorf("kolom '%s' diomean leuwih ti sakali", name)
+		}
+		seen[pos] = true
+		p.overwrite = append(p.overwrite, pos)
+	}
+	return p, nil
+}
+
+// key ngahijikeun nilai kolom konci hiji baris. false lamun aya nu kosong.
+func (p *upsertPlan) key(row []string) (string, bool) {
+	parts := make([]string, len(p.keys))
+	for i, pos := range p.keys {
+		v := strings.TrimSpace(cell(row, pos))
+		if v == "" {
+			return "", false
+		}
+		parts[i] = canonicalValue(v, p.cols[pos].Type)
+	}
+	return strings.Join(parts, "\x00"), true
+}
+
+func execUpsert(cmd *parser.Command, s *schema.Definition, t storage.Table, rows [][]string, ret *projection) (*ExecutionResult, error) {
+	p, err := compileUpsert(cmd, s)
+	if err != nil {
+		return nil, err
+	}
+
+	incoming := make(map[string]int)
+	for i, row := range rows {
+		k, ok := p.key(row)
+		if !ok {
+			continue
+		}
+		if j, dup := incoming[k]; dup {
+			return nil, fmt.Errorf("baris ka-%d: konci sarua jeung baris ka-%d", i+1, j+1)
+		}
+		incoming[k] = i
+	}
+
+	// BALIKKEUN: baris nu diomean heula, tuluy baris nu disimpen
+	var updatedRows, insertedRows [][]string
+	matched := make([]bool, len(rows))
+	merge := func(row []string) ([]string, bool, error) {
+		k, ok := p.key(row)
+		if !ok {
+			return nil, false, nil
+		}
+		i, hit := incoming[k]
+		if !hit {
+			return nil, false, nil
+		}
+		matched[i] = true
+		out := make([]string, max(len(row), len(p.cols)))
+		copy(out, row)
+		for _, pos := range p.overwrite {
+			out[pos] = rows[i][pos]
+		}
+		if ret != nil {
+			updatedRows = append(updatedRows, out)
+		}
+		return out, true, nil
+	}
+
+	inserted := 0
+	rest := func() ([][]string, error) {
+		var out [][]string
+		for i, row := range rows {
+			if !matched[i] {
+				out = append(out, row)
+			}
+		}
+		inserted, insertedRows = len(out), out
+		return out, nil
+	}
+
+	updated, err := t.Upsert(merge, rest)
+	if err != nil {
+		return nil, err
+	}
+	msg := fmt.Sprintf("✅ %d data asup, %d data diomean dina table '%s'", inserted, updated, cmd.Table)
+	return returningResult(ret, append(updatedRows, insertedRows...), msg)
+}
@@ -0,0 +1,463 @@
+package index
+
+import (
+	"cmp"
+	"slices"
+)
+
+// BTree nyaeta B-tree pikeun indeks kolom. Unggal Item = nilai kolom (Key)
+// + idéntitas baris (ID, contona offset baris dina file tabel). Key nu sarua
+// diidinan; urutanana dumasar (Key, ID).
+//
+// Tangkal bisa aya dina memori (New) atawa dina disk (Open): titik disk
+// ngan dimuat basa diliwatan ku cursor.
+type BTree struct {
+	cmp    func(a, b string) int
+	degree int
+	root   *Node
+	length int
+	load   Loader
+}
+
+// Loader maca titik disk dina offset nu dibikeun.
+type Loader func(off int64) (*Node, error)
+
+type Item struct {
+	Key string
+	ID  int64
+}
+
+// Node nyaeta hiji titik B-tree. Titik dina memori make Children; titik
+// nu dibaca ti disk make Offsets (posisi anak dina file).
+type Node struct {
+	Items    []Item
+	Children []*Node
+	Offsets  []int64
+}
+
+// Bound nyaeta wates rentang. Inclusive=false hartina wates teu kaasup.
+type Bound struct {
+	Key       string
+	Inclusive bool
+}
+
+// DefaultDegree: unggal titik eusina nepi ka 2*DefaultDegree-1 item.
+const DefaultDegree = 32
+
+func New(degree int, cmp func(a, b string) int) *BTree {
+	if degree < 2 {
+		degree = DefaultDegree
+	}
+	return &BTree{cmp: cmp, degree: degree, root: &Node{}}
+}
+
+// Open muka tangkal disk (ngan bisa dibaca). root geus dimuat; anak-anakna
+// dimuat ku load.
+func Open(degree int, cmp func(a, b string) int, root *Node, length int, load Loader) *BTree {
+	return &BTree{cmp: cmp, degree: degree, root: root, length: length, load: load}
+}
+
+func (t *BTree) Len() int { return t.length }
+
+func (t *BTree) Degree() int { return t.degree }
+
+// Root dipake pikeun nyimpen tangkal ka disk.
+func (t *BTree) Root() *Node { return t.root }
+
+func (n *Node) leaf() bool { return len(n.Children) == 0 && len(n.Offsets) == 0 }
+
+func (t *BTree) child(n *Node, i int) (*Node, error) {
+	if len(n.Children) > 0 {
+		return n.Children[i], nil
+	}
+	return t.load(n.Offsets[i])
+}
+
+func (t *BTree) less(a, b Item) bool {
+	if c := t.cmp(a.Key, b.Key); c != 0 {
+		return c < 0
+	}
+	return a.ID < b.ID
+}
+
+// Insert nambahkeun (key, id) kana tangkal. Ngan pikeun tangkal memori.
+func (t *BTree) Insert(key string, id int64) {
+	item := Item{Key: key, ID: id}
+	maxItems := 2*t.degree - 1
+
+	if len(t.root.Items) == maxItems {
+		old := t.root
+		t.root = &Node{Children: []*Node{old}}
+		t.splitChild(t.root, 0)
+	}
+
+	t.insertNonFull(t.root, item)
+	t.length++
+}
+
+func (t *BTree) insertNonFull(n *Node, item Item) {
+	maxItems := 2*t.degree - 1
+
+	for {
+		i := len(n.Items)
+		for i > 0 && t.less(item, n.Items[i-1]) {
+			i--
+		}
+
+		if n.leaf() {
+			n.Items = append(n.Items, Item{})
+			copy(n.Items[i+1:], n.Items[i:])
+			n.Items[i] = item
+			return
+		}
+
+		if len(n.Children[i].Items) == maxItems {
+			t.splitChild(n, i)
+			if t.less(n.Items[i], item) {
+				i++
+			}
+		}
+		n = n.Children[i]
+	}
+}
+
+// splitChild meulah anak ka-i (nu geus pinuh) jadi dua.
+func (t *BTree) splitChild(parent *Node, i int) {
+	d := t.degree
+	full := parent.Children[i]
+	mid := full.Items[d-1]
+
+	right := &Node{Items: append([]Item(nil), full.Items[d:]...)}
+	if !full.leaf() {
+		right.Children = append([]*Node(nil), full.Children[d:]...)
+		full.Children = full.Children[:d]
+	}
+	full.Items = full.Items[:d-1]
+
+	parent.Items = append(parent.Items, Item{})
+	copy(parent.Items[i+1:], parent.Items[i:])
+	parent.Items[i] = mid
+
+	parent.Children = append(parent.Children, nil)
+	copy(parent.Children[i+2:], parent.Children[i+1:])
+	parent.Children[i+1] = right
+}
+
+// =======================
+// CURSOR (RANGE SCAN)
+// =======================
+
+// Iterator ngaliwatan item hiji-hiji (Cursor atawa hasil Merge).
+type Iterator interface {
+	Next() bool
+	Item() Item
+	Err() error
+}
+
+type frame struct {
+	n *Node
+	i int
+}
+
+// Cursor ngaliwatan item dina rentang, naék atawa turun.
+type Cursor struct {
+	t       *BTree
+	lo, hi  *Bound
+	reverse bool
+	stack   []frame
+	item    Item
+	err     error
+}
+
+// Ascend mulangkeun cursor ti lo nepi ka hi (naék). nil = teu diwates.
+func (t *BTree) Ascend(lo, hi *Bound) *Cursor {
+	c := &Cursor{t: t, lo: lo, hi: hi}
+
+	n := t.root
+	for n != nil {
+		i := 0
+		if lo != nil {
+			for i < len(n.Items) && !c.afterLow(n.Items[i].Key) {
+				i++
+			}
+		}
+		c.stack = append(c.stack, frame{n, i})
+		if n.leaf() {
+			break
+		}
+		n = c.child(n, i)
+	}
+	return c
+}
+
+// Descend mulangkeun cursor ti hi nepi ka lo (turun). nil = teu diwates.
+func (t *BTree) Descend(lo, hi *Bound) *Cursor {
+	c := &Cursor{t: t, lo: lo, hi: hi, reverse: true}
+
+	n := t.root
+	for n != nil {
+		j := 0
+		for j < len(n.Items) && c.beforeHigh(n.Items[j].Key) {
+			j++
+		}
+		c.stack = append(c.stack, frame{n, j})
+		if n.leaf() {
+			break
+		}
+		n = c.child(n, j)
+	}
+	return c
+}
+
+// child ngamuat anak; kasalahan disimpen dina c.err.
+func (c *Cursor) child(n *Node, i int) *Node {
+	child, err := c.t.child(n, i)
+	if err != nil {
+		c.err = err
+		c.stack = nil
+		return nil
+	}
+	return child
+}
+
+func (c *Cursor) afterLow(key string) bool {
+	if c.lo == nil {
+		return true
+	}
+	r := c.t.cmp(key, c.lo.Key)
+	return r > 0 || (r == 0 && c.lo.Inclusive)
+}
+
+func (c *Cursor) beforeHigh(key string) bool {
+	if c.hi == nil {
+		return true
+	}
+	r := c.t.cmp(key, c.hi.Key)
+	return r < 0 || (r == 0 && c.hi.Inclusive)
+}
+
+// Next maju ka item salajengna. false lamun geus beak atawa aya kasalahan.
+func (c *Cursor) Next() bool {
+	if c.reverse {
+		return c.prev()
+	}
+
+	for len(c.stack) > 0 {
+		f := &c.stack[len(c.stack)-1]
+		if f.i >= len(f.n.Items) {
+			c.stack = c.stack[:len(c.stack)-1]
+			continue
+		}
+
+		item := f.n.Items[f.i]
+		f.i++
+
+		if !f.n.leaf() {
+			child := c.child(f.n, f.i)
+			for child != nil {
+				c.stack = append(c.stack, frame{child, 0})
+				if child.leaf() {
+					break
+				}
+				child = c.child(child, 0)
+			}
+			if c.err != nil {
+				return false
+			}
+		}
+
+		if !c.beforeHigh(item.Key) {
+			c.stack = nil
+			return false
+		}
+		c.item = item
+		return true
+	}
+	return false
+}
+
+func (c *Cursor) prev() bool {
+	for len(c.stack) > 0 {
+		f := &c.stack[len(c.stack)-1]
+		if f.i == 0 {
+			c.stack = c.stack[:len(c.stack)-1]
+			continue
+		}
+
+		f.i--
+		item := f.n.Items[f.i]
+
+		if !f.n.leaf() {
+			child := c.child(f.n, f.i)
+			for child != nil {
+				c.stack = append(c.stack, frame{child, len(child.Items)})
+				if child.leaf() {
+					break
+				}
+				child = c.child(child, len(child.Items))
+			}
+			if c.err != nil {
+				return false
+			}
+		}
+
+		if !c.afterLow(item.Key) {
+			c.stack = nil
+			return false
+		}
+		c.item = item
+		return true
+	}
+	return false
+}
+
+// Item mulangkeun item ayeuna (sanggeus Next mulangkeun true).
+func (c *Cursor) Item() Item { return c.item }
+
+// Err mulangkeun kasalahan maca titik disk, lamun aya.
+func (c *Cursor) Err() error { return c.err }
+
+// =======================
+// MERGE
+// =======================
+
+// Merge ngahijikeun dua iterator nu arahna sarua (duanana ti t.Ascend atawa
+// duanana ti t.Descend, tina tangkal nu comparator-na sarua) jadi hiji
+// aliran nu tetep runtuy.
+func (t *BTree) Merge(a, b Iterator, reverse bool) Iterator {
+	m := &merged{t: t, a: a, b: b, reverse: reverse}
+	m.hasA = a.Next()
+	m.hasB = b.Next()
+	return m
+}
+
+type merged struct {
+	t          *BTree
+	a, b       Iterator
+	hasA, hasB bool
+	reverse    bool
+	item       Item
+}
+
+func (m *merged) Next() bool {
+	if m.Err() != nil {
+		return false
+	}
+
+	switch {
+	case m.hasA && m.hasB:
+		takeA := m.t.less(m.a.Item(), m.b.Item())
+		if m.reverse {
+			takeA = !takeA
+		}
+		if takeA {
+			m.item = m.a.Item()
+			m.hasA = m.a.Next()
+		} else {
+			m.item = m.b.Item()
+			m.hasB = m.b.Next()
+		}
+	case m.hasA:
+		m.item = m.a.Item()
+		m.hasA = m.a.Next()
+	case m.hasB:
+		m.item = m.b.Item()
+		m.hasB = m.b.Next()
+	default:
+		return false
+	}
+	return true
+}
+
+func (m *merged) Item() Item { return m.item }
+
+func (m *merged) Err() error {
+	if err := m.a.Err(); err != nil {
+		return err
+	}
+	return m.b.Err()
+}
+
+// =======================
+// URUTAN ID
+// =======================
+
+// TiesByID mulangkeun item ti it (hasil t.Descend) kalawan key turun, tapi
+// item nu key-na sarua diurutkeun numutkeun ID naék, saperti sort nu
+// stabil kana baris nu runtuy numutkeun ID.
+func (t *BTree) TiesByID(it Iterator) Iterator {
+	return &tiesByID{t: t, src: it}
+}
+
+type tiesByID struct {
+	t       *BTree
+	src     Iterator
+	run     []Item // key nu sarua, ID turun; dicokot ti tukang
+	next    Item
+	hasNext bool
+	started bool
+	item    Item
+}
+
+func (r *tiesByID) Next() bool {
+	if len(r.run) == 0 {
+		if !r.started {
+			r.started = true
+			if r.hasNext = r.src.Next(); r.hasNext {
+				r.next = r.src.Item()
+			}
+		}
+		if !r.hasNext {
+			return false
+		}
+		r.run = append(r.run, r.next)
+		for {
+			if r.hasNext = r.src.Next(); !r.hasNext {
+				break
+			}
+			r.next = r.src.Item()
+			if r.t.cmp(r.next.Key, r.run[0].Key) != 0 {
+				break
+			}
+			r.run = append(r.run, r.next)
+		}
+		if r.src.Err() != nil {
+			return false
+		}
+	}
+	r.item = r.run[len(r.run)-1]
+	r.run = r.run[:len(r.run)-1]
+	return true
+}
+
+func (r *tiesByID) Item() Item { return r.item }
+
+func (r *tiesByID) Err() error { return r.src.Err() }
+
+// ByID ngumpulkeun sakabéh item it, tuluy mulangkeunana numutkeun ID naék.
+func ByID(it Iterator) Iterator {
+	var items []Item
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	slices.SortFunc(items, func(a, b Item) int { return cmp.Compare(a.ID, b.ID) })
+	return &itemList{items: items, err: it.Err(), pos: -1}
+}
+
+type itemList struct {
+	items []Item
+	err   error
+	pos   int
+}
+
+func (l *itemList) Next() bool {
+	if l.err != nil || l.pos+1 >= len(l.items) {
+		return false
+	}
+	l.pos++
+	return true
+}
+
+func (l *itemList) Item() Item { return l.items[l.pos] }
+
+func (l *itemList) Err() error { return l.err }
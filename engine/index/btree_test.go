@@ -0,0 +1,156 @@
+package index
+
+import (
+	"math/rand"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func byNumber(a, b string) int {
+	x, _ := strconv.Atoi(a)
+	y, _ := strconv.Atoi(b)
+	return x - y
+}
+
+func collect(t *testing.T, it Iterator) []Item {
+	t.Helper()
+	var items []Item
+	for it.Next() {
+		items = append(items, it.Item())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return items
+}
+
+// sampleTree: 500 item kalawan loba Key nu sarua, diselapkeun acak supaya
+// titikna sering dibagi (degree 2).
+func sampleTree() (*BTree, []Item) {
+	var want []Item
+	for id := range 500 {
+		want = append(want, Item{Key: strconv.Itoa(id % 37), ID: int64(id)})
+	}
+	shuffled := slices.Clone(want)
+	rand.New(rand.NewSource(1)).Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	tree := New(2, byNumber)
+	for _, it := range shuffled {
+		tree.Insert(it.Key, it.ID)
+	}
+	slices.SortFunc(want, func(a, b Item) int {
+		if c := byNumber(a.Key, b.Key); c != 0 {
+			return c
+		}
+		return int(a.ID - b.ID)
+	})
+	return tree, want
+}
+
+func TestBTreeAscendDescend(t *testing.T) {
+	tree, want := sampleTree()
+	if tree.Len() != len(want) {
+		t.Fatalf("Len %d, kuduna %d", tree.Len(), len(want))
+	}
+	if got := collect(t, tree.Ascend(nil, nil)); !slices.Equal(got, want) {
+		t.Error("Ascend teu runtuy")
+	}
+	rev := slices.Clone(want)
+	slices.Reverse(rev)
+	if got := collect(t, tree.Descend(nil, nil)); !slices.Equal(got, rev) {
+		t.Error("Descend teu runtuy")
+	}
+}
+
+func TestBTreeRange(t *testing.T) {
+	tree, all := sampleTree()
+	tests := []struct {
+		lo, hi *Bound
+		keep   func(k int) bool
+	}{
+		{&Bound{Key: "5", Inclusive: true}, &Bound{Key: "9", Inclusive: true}, func(k int) bool { return k >= 5 && k <= 9 }},
+		{&Bound{Key: "5"}, &Bound{Key: "9"}, func(k int) bool { return k > 5 && k < 9 }},
+		{nil, &Bound{Key: "3"}, func(k int) bool { return k < 3 }},
+		{&Bound{Key: "30", Inclusive: true}, nil, func(k int) bool { return k >= 30 }},
+		{&Bound{Key: "40", Inclusive: true}, nil, func(k int) bool { return false }},
+	}
+	for _, tt := range tests {
+		var want []Item
+		for _, it := range all {
+			if k, _ := strconv.Atoi(it.Key); tt.keep(k) {
+				want = append(want, it)
+			}
+		}
+		if got := collect(t, tree.Ascend(tt.lo, tt.hi)); !slices.Equal(got, want) {
+			t.Errorf("Ascend %v %v: %d item, kuduna %d", tt.lo, tt.hi, len(got), len(want))
+		}
+		slices.Reverse(want)
+		if got := collect(t, tree.Descend(tt.lo, tt.hi)); !slices.Equal(got, want) {
+			t.Errorf("Descend %v %v: %d item, kuduna %d", tt.lo, tt.hi, len(got), len(want))
+		}
+	}
+}
+
+func TestBTreeOnDisk(t *testing.T) {
+	tree, want := sampleTree()
+
+	// simulasi tangkal disk: anak dirujuk ku offset, dimuat ku Loader
+	var nodes []*Node
+	var store func(n *Node) int64
+	store = func(n *Node) int64 {
+		disk := &Node{Items: n.Items}
+		for _, c := range n.Children {
+			disk.Offsets = append(disk.Offsets, store(c))
+		}
+		nodes = append(nodes, disk)
+		return int64(len(nodes) - 1)
+	}
+	off := store(tree.Root())
+	loads := 0
+	disk := Open(tree.Degree(), byNumber, nodes[off], tree.Len(), func(off int64) (*Node, error) {
+		loads++
+		return nodes[off], nil
+	})
+
+	got := collect(t, disk.Ascend(&Bound{Key: "36", Inclusive: true}, nil))
+	if n := len(got); n != 13 || got[0] != (Item{Key: "36", ID: 36}) {
+		t.Errorf("rentang disk: %v", got)
+	}
+	if loads == 0 || loads >= len(nodes) {
+		t.Errorf("rentang leutik kuduna ngan muka sawatara titik, muka %d tina %d", loads, len(nodes))
+	}
+	if got := collect(t, disk.Ascend(nil, nil)); !slices.Equal(got, want) {
+		t.Error("Ascend disk teu runtuy")
+	}
+}
+
+func TestBTreeMergeAndTies(t *testing.T) {
+	a, b := New(2, strings.Compare), New(2, strings.Compare)
+	for _, it := range []Item{{"a", 1}, {"b", 4}, {"c", 2}} {
+		a.Insert(it.Key, it.ID)
+	}
+	for _, it := range []Item{{"b", 3}, {"b", 5}, {"d", 0}} {
+		b.Insert(it.Key, it.ID)
+	}
+
+	want := []Item{{"a", 1}, {"b", 3}, {"b", 4}, {"b", 5}, {"c", 2}, {"d", 0}}
+	if got := collect(t, a.Merge(a.Ascend(nil, nil), b.Ascend(nil, nil), false)); !slices.Equal(got, want) {
+		t.Errorf("Merge naék: %v", got)
+	}
+
+	// turun, tapi Key nu sarua tetep dumasar ID naék
+	want = []Item{{"d", 0}, {"c", 2}, {"b", 3}, {"b", 4}, {"b", 5}, {"a", 1}}
+	merged := a.Merge(a.Descend(nil, nil), b.Descend(nil, nil), true)
+	if got := collect(t, a.TiesByID(merged)); !slices.Equal(got, want) {
+		t.Errorf("TiesByID: %v", got)
+	}
+
+	want = []Item{{"d", 0}, {"a", 1}, {"c", 2}, {"b", 3}, {"b", 4}, {"b", 5}}
+	if got := collect(t, ByID(a.Merge(a.Ascend(nil, nil), b.Ascend(nil, nil), false))); !slices.Equal(got, want) {
+		t.Errorf("ByID: %v", got)
+	}
+}
@@ -1,5 +1,7 @@
 package parser
 
+import "strings"
+
 type CommandType string
 
 const (
@@ -8,24 +10,263 @@ const (
 	CmdSelect CommandType = "SELECT"
 	CmdUpdate CommandType = "UPDATE"
 	CmdDelete CommandType = "DELETE"
+
+	CmdCreateIndex CommandType = "CREATE_INDEX"
+
+	// DDL: PICEUN TABEL|DATABASE, KOSONGKEUN, GANTI NGARAN TABEL|DATABASE
+	CmdDropTable      CommandType = "DROP_TABLE"
+	CmdDropDatabase   CommandType = "DROP_DATABASE"
+	CmdTruncate       CommandType = "TRUNCATE"
+	CmdRenameTable    CommandType = "RENAME_TABLE"
+	CmdRenameDatabase CommandType = "RENAME_DATABASE"
+
+	// transaksi: MIMITIAN / ANGGEUSAN / BATALKEUN
+	CmdBegin    CommandType = "BEGIN"
+	CmdCommit   CommandType = "COMMIT"
+	CmdRollback CommandType = "ROLLBACK"
 )
 
 type Command struct {
 	Type    CommandType
 	Table   string
 	Data    string    
-	Updates map[string]string 
-	Where   []Condition
+	Columns []string   // SIMPEN <tabel> (<kolom>, ...) NILAI ... (nil = sadaya kolom)
+	Rows    [][]string // SIMPEN: nilai unggal baris
+	Nulls   [][]bool   // SIMPEN ... NILAI: true = KOSONG (null); nil = euweuh null
+	Source  *Command   // SIMPEN <tabel> TINGALI ... / DAMEL <tabel> SALAKU TINGALI ...
+
+	// SIMPEN ... ATAWA OMEAN DUMASAR <Conflict>, ... [JADI <Overwrite>, ...]
+	Conflict  []string
+	Overwrite []string
+
+	// SIMPEN / OMEAN / MICEUN ... BALIKKEUN <kolom>, ... (nil = euweuh)
+	Returning []SelectItem
+	Updates []Assignment // OMEAN ... JADI <kolom> = <nilai>, ...
+	Where   Expr // DIMANA (nil = euweuh)
+
+	// TINGALI <kolom>, ... TI <tabel> (nil = sadaya kolom)
+	Fields []SelectItem
+
+	// TINGALI ... TI <Table> [SALAKU <TableAlias>] GABUNG ...
+	TableAlias string
+	Joins      []Join
+
+	// KUMPULKEUN DUMASAR <kolom>, ... [ANU <kondisi>]
+	GroupBy []string
+	Having  Expr
+
+	// SADAYANA: OMEAN / MICEUN ngahaja tanpa DIMANA (sakabéh baris)
+	All bool
+
+	// RUNTUYKEUN <kolom> [NAEK|TURUN], ...
+	OrderBy []OrderItem
 
-	OrderBy   string 
-	OrderDesc bool   
 	Limit     int   
 	Offset    int    
+
+	// JELASKEUN <query>: ngan mintonkeun plan, teu dijalankeun
+	Explain bool
+
+	// DAMEL INDEKS <Index> DINA <Table>(<Column>)
+	Index  string
+	Column string
+
+	// PICEUN DATABASE <Database> / GANTI NGARAN ... JADI <NewName>
+	Database string
+	NewName  string
+
+	// LAMUN AYA: objék nu teu aya dilewat, lain error
+	IfExists bool
+}
+
+// =======================
+// EKSPRÉSI (DIMANA)
+// =======================
+
+// Expr nyaéta titik tangkal éksprési DIMANA:
+//
+//	umur > 20 ATAWA (divisi = IT SARENG TEU aktif = true)
+//
+// Binary{ATAWA, Binary{>, umur, 20}, Binary{SARENG, ...}}
+type Expr interface {
+	String() string
+}
+
+// Ident nyaéta kecap tanpa tanda petik: ngaran kolom, atawa nilai lamun
+// teu aya kolom nu ngaranna kitu (DIMANA divisi = IT).
+type Ident struct {
+	Name string
+}
+
+// Literal nyaéta angka atawa string ('Asep'). Quoted = ditulis dina tanda petik.
+type Literal struct {
+	Value  string
+	Quoted bool
+}
+
+// Binary: SARENG, ATAWA, babandingan (= != < > <= >= JIGA COCOG), atawa itungan
+// (+ - * / %).
+type Binary struct {
+	Op          string
+	Left, Right Expr
+}
+
+// Not nyaéta TEU <éksprési>.
+type Not struct {
+	X Expr
+}
+
+// In nyaéta <nilai> DI (<nilai>, ...). TEU DI = Not{In}.
+type In struct {
+	X    Expr
+	List []Expr
+}
+
+// Between nyaéta <nilai> ANTARA <nilai> JEUNG <nilai> (dua watesna kaasup).
+type Between struct {
+	X, Low, High Expr
+}
+
+// IsEmpty nyaéta <nilai> KOSONG (nilai kosong / null).
+type IsEmpty struct {
+	X Expr
+}
+
+// Aggregate nyaéta fungsi agrégat: ITUNG(*), JUMLAH(gaji), RATA(gaji),
+// PANGLEUTIKNA(masuk), PANGGEDENA(masuk).
+type Aggregate struct {
+	Func string
+	Arg  Expr // nil = * (ngan ITUNG)
 }
 
-type Condition struct {
-	Field    string
-	Operator string
-	Value    string
-	LogicOp  string
-}
\ No newline at end of file
+func (e *Ident) String() string { return e.Name }
+
+func (e *Aggregate) String() string {
+	if e.Arg == nil {
+		return e.Func + "(*)"
+	}
+	return e.Func + "(" + e.Arg.String() + ")"
+}
+
+func (e *Literal) String() string {
+	if !e.Quoted {
+		return e.Value
+	}
+	return "'" + strings.ReplaceAll(e.Value, "'", "''") + "'"
+}
+
+func (e *Binary) String() string {
+	return operandString(e.Left, e.Op) + " " + e.Op + " " + operandString(e.Right, e.Op)
+}
+
+func (e *Not) String() string {
+	return "TEU " + operandString(e.X, "TEU")
+}
+
+func (e *In) String() string {
+	items := make([]string, len(e.List))
+	for i, x := range e.List {
+		items[i] = x.String()
+	}
+	return operandString(e.X, "DI") + " DI (" + strings.Join(items, ", ") + ")"
+}
+
+func (e *Between) String() string {
+	return operandString(e.X, "ANTARA") + " ANTARA " + operandString(e.Low, "ANTARA") + " JEUNG " + operandString(e.High, "ANTARA")
+}
+
+func (e *IsEmpty) String() string {
+	return operandString(e.X, "KOSONG") + " KOSONG"
+}
+
+// precedence: beuki gedé beuki pageuh ngabeungkeut.
+func precedence(op string) int {
+	switch op {
+	case "ATAWA":
+		return 1
+	case "SARENG":
+		return 2
+	case "TEU":
+		return 3
+	case "+", "-":
+		return 5
+	case "*", "/", "%":
+		return 6
+	}
+	return 4 // babandingan
+}
+
+// operandString nambahkeun kurung lamun anak leuwih leupas batan indungna.
+func operandString(e Expr, parent string) string {
+	var op string
+	switch x := e.(type) {
+	case *Binary:
+		op = x.Op
+	case *Not:
+		op = "TEU"
+	default:
+		return e.String()
+	}
+	if precedence(op) < precedence(parent) || (parent != "ATAWA" && parent != "SARENG" && precedence(op) == precedence(parent)) {
+		return "(" + e.String() + ")"
+	}
+	return e.String()
+}
+
+// SelectItem nyaéta hiji kolom hasil TINGALI: <éksprési> [SALAKU <alias>],
+// atawa * (sadaya kolom tabel).
+type SelectItem struct {
+	Expr  Expr
+	Alias string
+	Star  bool
+}
+
+// Name nyaéta ngaran kolom hasil: alias, atawa éksprésina.
+func (it SelectItem) Name() string {
+	if it.Alias != "" {
+		return it.Alias
+	}
+	if it.Star {
+		return "*"
+	}
+	return it.Expr.String()
+}
+
+// Assignment nyaéta hiji <kolom> = <nilai> dina OMEAN ... JADI. Value bisa
+// ngarujuk kolom baris nu keur diomean (gaji = gaji * 1.1).
+type Assignment struct {
+	Column string
+	Value  Expr
+}
+
+func (a Assignment) String() string { return a.Column + " = " + a.Value.String() }
+
+// OrderItem nyaéta hiji kolom RUNTUYKEUN. Desc = TURUN.
+type OrderItem struct {
+	Column string
+	Desc   bool
+}
+
+func (o OrderItem) String() string {
+	if o.Desc {
+		return o.Column + " TURUN"
+	}
+	return o.Column + " NAEK"
+}
+
+// Join nyaéta GABUNG [KENCA] <tabel> [SALAKU <alias>] DINA <kondisi>.
+// KENCA (left join) tetep mulangkeun baris kénca nu teu boga pasangan.
+type Join struct {
+	Table string
+	Alias string
+	Left  bool
+	On    Expr
+}
+
+// Name nyaéta ngaran nu dipaké pikeun kolom (alias.kolom atawa tabel.kolom).
+func (j Join) Name() string {
+	if j.Alias != "" {
+		return j.Alias
+	}
+	return j.Table
+}
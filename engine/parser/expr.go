@@ -0,0 +1,327 @@
+package parser
+
+import "strings"
+
+// =======================
+// DIMANA (RECURSIVE DESCENT)
+// =======================
+//
+// Urutan ti nu pangleupasna:
+//
+//	ATAWA  <  SARENG  <  TEU  <  babandingan (= != < > <= >= JIGA COCOG DI ANTARA KOSONG)  <  + -  <  * / %
+//
+// jadi "a = 1 ATAWA b = 2 SARENG c = 3" hartina "a = 1 ATAWA (b = 2 SARENG c = 3)".
+// Kurung dipaké pikeun ngarobah urutan.
+
+// comparisonOps: operator babandingan nu dikenal, jeung wangun bakuna.
+var comparisonOps = map[string]string{
+	"=": "=", "==": "=", "!=": "!=", "<>": "!=",
+	"<": "<", ">": ">", "<=": "<=", ">=": ">=",
+}
+
+// parseWhere maca éksprési DIMANA.
+func parseWhere(ts *tokenStream) (Expr, error) {
+	return parseOr(ts)
+}
+
+func parseOr(ts *tokenStream) (Expr, error) {
+	left, err := parseAnd(ts)
+	if err != nil {
+		return nil, err
+	}
+	for !ts.atUpsert() && ts.acceptKeyword("ATAWA") {
+		right, err := parseAnd(ts)
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: "ATAWA", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func parseAnd(ts *tokenStream) (Expr, error) {
+	left, err := parseNot(ts)
+	if err != nil {
+		return nil, err
+	}
+	for ts.acceptKeyword("SARENG") {
+		right, err := parseNot(ts)
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: "SARENG", Left: left, Right: right}
+	}
+	return left, nil
+}
+
+func parseNot(ts *tokenStream) (Expr, error) {
+	if ts.acceptKeyword("TEU") {
+		x, err := parseNot(ts)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+	return parseComparison(ts)
+}
+
+// parseComparison: <nilai> <op> <nilai>, atawa (<éksprési>).
+func parseComparison(ts *tokenStream) (Expr, error) {
+	// "(" bisa muka grup DIMANA atawa itungan: (a = 1 ATAWA b = 2) vs
+	// (gaji + bonus) > 10. Dicoba salaku grup heula.
+	if ts.peek().Kind == TokenPunct && ts.peek().Text == "(" {
+		start := ts.pos
+		ts.next()
+		x, err := parseOr(ts)
+		if err == nil {
+			if err = ts.expect(")"); err == nil {
+				return x, nil
+			}
+		}
+		failedAt := ts.pos
+
+		ts.pos = start
+		cmp, cmpErr := parseValueComparison(ts)
+		if cmpErr != nil && ts.pos < failedAt {
+			return nil, err
+		}
+		return cmp, cmpErr
+	}
+	return parseValueComparison(ts)
+}
+
+func parseValueComparison(ts *tokenStream) (Expr, error) {
+	left, err := parseValue(ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// <nilai> TEU DI (...), TEU ANTARA, TEU KOSONG, TEU COCOG, TEU JIGA
+	if ts.acceptKeyword("TEU") {
+		if t := ts.peek(); !t.Is("DI") && !t.Is("ANTARA") && !t.Is("KOSONG") && !t.Is("COCOG") && !t.Is("JIGA") {
+			return nil, ts.errorf("saatos TEU butuh DI, ANTARA, KOSONG, COCOG atawa JIGA, lain %s", t.quoted())
+		}
+		x, err := parsePredicate(ts, left)
+		if err != nil {
+			return nil, err
+		}
+		return &Not{X: x}, nil
+	}
+	return parsePredicate(ts, left)
+}
+
+// parsePredicate maca operator saatos nilai kénca:
+//
+//	= != < > <= >= JIGA COCOG ~ !~ <nilai>
+//	DI (<nilai>, ...)
+//	ANTARA <nilai> JEUNG <nilai>
+//	KOSONG
+func parsePredicate(ts *tokenStream, left Expr) (Expr, error) {
+	op := ts.peek()
+	var name string
+	switch {
+	case op.Is("DI"):
+		ts.next()
+		return parseInList(ts, left)
+	case op.Is("ANTARA"):
+		ts.next()
+		return parseBetween(ts, left)
+	case op.Is("KOSONG"):
+		ts.next()
+		return &IsEmpty{X: left}, nil
+	case op.Is("JIGA"), op.Is("COCOG"):
+		name = strings.ToUpper(op.Text)
+	case op.Kind == TokenOperator && (op.Text == "~" || op.Text == "!~"):
+		// ~ = COCOG, !~ = TEU COCOG
+		ts.next()
+		right, err := parseValue(ts)
+		if err != nil {
+			return nil, err
+		}
+		var x Expr = &Binary{Op: "COCOG", Left: left, Right: right}
+		if op.Text == "!~" {
+			x = &Not{X: x}
+		}
+		return x, nil
+	case op.Kind == TokenOperator && comparisonOps[op.Text] != "":
+		name = comparisonOps[op.Text]
+	default:
+		return nil, ts.errorf("butuh operator babandingan, lain %s", op.quoted())
+	}
+	ts.next()
+
+	right, err := parseValue(ts)
+	if err != nil {
+		return nil, err
+	}
+	return &Binary{Op: name, Left: left, Right: right}, nil
+}
+
+// parseInList: DI (<nilai>, <nilai>, ...). DI geus dibaca.
+func parseInList(ts *tokenStream, left Expr) (Expr, error) {
+	if err := ts.expect("("); err != nil {
+		return nil, err
+	}
+	in := &In{X: left}
+	for {
+		x, err := parseValue(ts)
+		if err != nil {
+			return nil, err
+		}
+		in.List = append(in.List, x)
+		if !ts.accept(",") {
+			break
+		}
+	}
+	if err := ts.expect(")"); err != nil {
+		return nil, err
+	}
+	return in, nil
+}
+
+// parseBetween: ANTARA <nilai> JEUNG <nilai>. ANTARA geus dibaca.
+func parseBetween(ts *tokenStream, left Expr) (Expr, error) {
+	low, err := parseValue(ts)
+	if err != nil {
+		return nil, err
+	}
+	if !ts.acceptKeyword("JEUNG") {
+		return nil, ts.errorf("ANTARA butuh JEUNG, lain %s", ts.peek().quoted())
+	}
+	high, err := parseValue(ts)
+	if err != nil {
+		return nil, err
+	}
+	return &Between{X: left, Low: low, High: high}, nil
+}
+
+// =======================
+// NILAI (ITUNGAN)
+// =======================
+
+// parseValue maca éksprési nilai: operand disambung ku + - * / %, contona
+// gaji * 1.1 atawa (gaji + bonus) / 2.
+func parseValue(ts *tokenStream) (Expr, error) {
+	left, err := parseTerm(ts)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ts.peek()
+		if op.Kind != TokenOperator || (op.Text != "+" && op.Text != "-") {
+			return left, nil
+		}
+		ts.next()
+		right, err := parseTerm(ts)
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op.Text, Left: left, Right: right}
+	}
+}
+
+func parseTerm(ts *tokenStream) (Expr, error) {
+	left, err := parseFactor(ts)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := ts.peek()
+		if op.Kind != TokenOperator || (op.Text != "*" && op.Text != "/" && op.Text != "%") {
+			return left, nil
+		}
+		ts.next()
+		right, err := parseFactor(ts)
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op.Text, Left: left, Right: right}
+	}
+}
+
+func parseFactor(ts *tokenStream) (Expr, error) {
+	if ts.accept("(") {
+		x, err := parseValue(ts)
+		if err != nil {
+			return nil, err
+		}
+		if err := ts.expect(")"); err != nil {
+			return nil, err
+		}
+		return x, nil
+	}
+	// angka négatif: -5, -gaji (= 0 - gaji)
+	if ts.accept("-") {
+		if t := ts.peek(); t.Kind == TokenNumber {
+			ts.next()
+			return &Literal{Value: "-" + t.Text}, nil
+		}
+		x, err := parseFactor(ts)
+		if err != nil {
+			return nil, err
+		}
+		return &Binary{Op: "-", Left: &Literal{Value: "0"}, Right: x}, nil
+	}
+	return parseOperand(ts)
+}
+
+// parseOperand: ngaran kolom, angka, string, atawa kecap nilai.
+func parseOperand(ts *tokenStream) (Expr, error) {
+	t := ts.peek()
+	switch t.Kind {
+	case TokenWord:
+		if isKeyword(t) {
+			return nil, ts.errorf("butuh kolom atawa nilai, lain %s", t.quoted())
+		}
+		ts.next()
+		if fn := strings.ToUpper(t.Text); aggregateFuncs[fn] && ts.peek().Text == "(" && ts.peek().Kind == TokenPunct {
+			return parseAggregate(ts, fn)
+		}
+		return &Ident{Name: t.Text}, nil
+	case TokenNumber:
+		ts.next()
+		return &Literal{Value: t.Text}, nil
+	case TokenString:
+		ts.next()
+		return &Literal{Value: t.Text, Quoted: true}, nil
+	}
+	return nil, ts.errorf("butuh kolom atawa nilai, lain %s", t.quoted())
+}
+
+// aggregateFuncs: ITUNG (COUNT), JUMLAH (SUM), RATA (AVG),
+// PANGLEUTIKNA (MIN), PANGGEDENA (MAX).
+var aggregateFuncs = map[string]bool{
+	"ITUNG": true, "JUMLAH": true, "RATA": true, "PANGLEUTIKNA": true, "PANGGEDENA": true,
+}
+
+// parseAggregate: <fungsi>(<nilai>) atawa ITUNG(*). Ngaran fungsi geus dibaca.
+func parseAggregate(ts *tokenStream, fn string) (Expr, error) {
+	ts.next() // (
+	agg := &Aggregate{Func: fn}
+	if !ts.accept("*") {
+		x, err := parseValue(ts)
+		if err != nil {
+			return nil, err
+		}
+		agg.Arg = x
+	} else if fn != "ITUNG" {
+		return nil, ts.errorf("%s(*) teu bisa, ngan ITUNG(*)", fn)
+	}
+	if err := ts.expect(")"); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// keywords nu teu bisa jadi operand tanpa tanda petik.
+var keywords = map[string]bool{
+	"SARENG": true, "ATAWA": true, "TEU": true, "JIGA": true,
+	"RUNTUYKEUN": true, "SAKADAR": true, "LIWATAN": true, "DIMANA": true,
+	"SALAKU": true, "KUMPULKEUN": true, "ANU": true, "GABUNG": true, "DINA": true,
+	"DI": true, "ANTARA": true, "JEUNG": true, "KOSONG": true, "COCOG": true,
+	"BALIKKEUN": true,
+}
+
+func isKeyword(t Token) bool {
+	return t.Kind == TokenWord && keywords[strings.ToUpper(t.Text)]
+}
@@ -0,0 +1,91 @@
+package parser
+
+import (
+	"strings"
+	"testing"
+)
+
+// tree nulis éksprési kalawan kurung dina unggal titik, supaya urutan
+// beungkeutanana katingali.
+func tree(e Expr) string {
+	switch x := e.(type) {
+	case *Binary:
+		return "(" + tree(x.Left) + " " + x.Op + " " + tree(x.Right) + ")"
+	case *Not:
+		return "(TEU " + tree(x.X) + ")"
+	case *In:
+		items := make([]string, len(x.List))
+		for i, it := range x.List {
+			items[i] = tree(it)
+		}
+		return "(" + tree(x.X) + " DI [" + strings.Join(items, " ") + "])"
+	case *Between:
+		return "(" + tree(x.X) + " ANTARA " + tree(x.Low) + " " + tree(x.High) + ")"
+	case *IsEmpty:
+		return "(" + tree(x.X) + " KOSONG)"
+	}
+	return e.String()
+}
+
+func TestWherePrecedence(t *testing.T) {
+	tests := []struct{ where, want string }{
+		{"a = 1 ATAWA b = 2 SARENG c = 3", "((a = 1) ATAWA ((b = 2) SARENG (c = 3)))"},
+		{"(a = 1 ATAWA b = 2) SARENG c = 3", "(((a = 1) ATAWA (b = 2)) SARENG (c = 3))"},
+		{"a = 1 SARENG b = 2 SARENG c = 3", "(((a = 1) SARENG (b = 2)) SARENG (c = 3))"},
+		{"TEU a = 1 SARENG b = 2", "((TEU (a = 1)) SARENG (b = 2))"},
+		{"TEU (a = 1 ATAWA b = 2)", "(TEU ((a = 1) ATAWA (b = 2)))"},
+		{"a + b * 2 > c - 1", "((a + (b * 2)) > (c - 1))"},
+		{"(a + b) * 2 = 10", "(((a + b) * 2) = 10)"},
+		{"a - b - c = 0", "(((a - b) - c) = 0)"},
+		{"umur TEU DI (20, 30) ATAWA nama KOSONG", "((TEU (umur DI [20 30])) ATAWA (nama KOSONG))"},
+		{"umur ANTARA 20 JEUNG 30 SARENG nama TEU KOSONG", "((umur ANTARA 20 30) SARENG (TEU (nama KOSONG)))"},
+		{"nama COCOG '^A' ATAWA nama JIGA '%a'", "((nama COCOG '^A') ATAWA (nama JIGA '%a'))"},
+	}
+	for _, tt := range tests {
+		cmd, err := Parse("TINGALI t DIMANA " + tt.where)
+		if err != nil {
+			t.Errorf("%s: %v", tt.where, err)
+			continue
+		}
+		if got := tree(cmd.Where); got != tt.want {
+			t.Errorf("%s:\nmeunang %s\nkuduna  %s", tt.where, got, tt.want)
+		}
+	}
+}
+
+func TestWhereStringRoundTrip(t *testing.T) {
+	for _, where := range []string{
+		"a = 1 ATAWA b = 2 SARENG c = 3",
+		"(a = 1 ATAWA b = 2) SARENG c = 3",
+		"TEU (a = 1 ATAWA b = 2)",
+		"(a + b) * 2 = 10",
+		"a - (b - c) = 0",
+		"nama = 'Jum''at'",
+	} {
+		cmd, err := Parse("TINGALI t DIMANA " + where)
+		if err != nil {
+			t.Fatalf("%s: %v", where, err)
+		}
+		again, err := Parse("TINGALI t DIMANA " + cmd.Where.String())
+		if err != nil {
+			t.Fatalf("%s: %v", cmd.Where, err)
+		}
+		if tree(again.Where) != tree(cmd.Where) {
+			t.Errorf("%s: String() %q robah hartina", where, cmd.Where)
+		}
+	}
+}
+
+func TestWhereErrors(t *testing.T) {
+	for _, where := range []string{
+		"(a = 1",
+		"a = 1 SARENG",
+		"a TEU = 1",
+		"a DI ()",
+		"a ANTARA 1",
+	} {
+		if _, err := Parse("TINGALI t DIMANA " + where); err == nil {
+			t.Errorf("%s: kuduna error", where)
+		}
+	}
+}
@@ -0,0 +1,505 @@
+package parser
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// =======================
+// LEXER
+// =======================
+//
+// Tokenize megatkeun query MaungQL jadi token:
+//
+//	TINGALI pegawai DIMANA nama = 'Asep Sunandar' SARENG umur>20 -- koméntar
+//
+// - kecap (Word)   : ngaran tabel / kolom / keyword, atawa nilai tanpa tanda
+//                    petik. '.', ':' jeung '@' kaasup, kitu deui tanggal
+//                    (2024-01-10); '\x' ngaloloskeun hiji karakter.
+// - angka (Number) : 20, -5, 3.14, 1e6
+// - string         : 'Asep' atawa "Asep"; escape \' \" \\ \n \t \r, jeung
+//                    '' di jero '...'
+// - operator       : = == != <> < > <= >= + - * / % ~ !~
+// - tanda          : ( ) , | ;
+// - "--" nepi ka tungtung baris nyaéta koméntar (dipiceun).
+//
+// Data SIMPEN format heubeul (SIMPEN t 1|Jum'at|Kopi & Teh) teu ditokenkeun:
+// unggal nilai nepi ka '|' salajengna dicokot sakumaha ditulis (tingali
+// lexLegacyRow).
+
+type TokenKind int
+
+const (
+	TokenEOF TokenKind = iota
+	TokenWord
+	TokenNumber
+	TokenString
+	TokenOperator
+	TokenPunct
+)
+
+func (k TokenKind) String() string {
+	switch k {
+	case TokenWord:
+		return "kecap"
+	case TokenNumber:
+		return "angka"
+	case TokenString:
+		return "string"
+	case TokenOperator:
+		return "operator"
+	case TokenPunct:
+		return "tanda"
+	}
+	return "tungtung query"
+}
+
+// Token nyaéta hiji unit query. Text pikeun TokenString geus di-unescape;
+// Raw mangrupa téks aslina.
+type Token struct {
+	Kind TokenKind
+	Text string
+	Raw  string
+
+	Line int // mimiti ti 1
+	Col  int // mimiti ti 1 (karakter, lain byte)
+
+	Start int // offset byte dina input
+	End   int
+}
+
+// Is: token kecap nu sarua jeung keyword (teu malire gedé-leutik hurup).
+func (t Token) Is(keyword string) bool {
+	return t.Kind == TokenWord && strings.EqualFold(t.Text, keyword)
+}
+
+// quoted dipaké dina pesen kasalahan: 'x', atawa "tungtung query".
+func (t Token) quoted() string {
+	if t.Kind == TokenEOF {
+		return "tungtung query"
+	}
+	return "'" + t.Raw + "'"
+}
+
+// SyntaxError nyaéta kasalahan sintaks kalawan posisi dina query.
+type SyntaxError struct {
+	Msg  string
+	Line int
+	Col  int
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s (baris %d, kolom %d)", e.Msg, e.Line, e.Col)
+}
+
+type lexer struct {
+	input string
+	pos   int
+	line  int
+	col   int
+	toks  []Token
+}
+
+// Tokenize mulangkeun token-token input (tanpa koméntar), ditungtungan ku
+// hiji TokenEOF.
+func Tokenize(input string) ([]Token, error) {
+	lx := &lexer{input: input, line: 1, col: 1}
+
+	for {
+		lx.skipSpace()
+		if lx.pos >= len(lx.input) {
+			break
+		}
+
+		var err error
+		c := lx.input[lx.pos]
+		switch {
+		case lx.atLegacyRow():
+			lx.lexLegacyRow()
+		case c == '-' && lx.peekByte(1) == '-':
+			lx.skipComment()
+		case c == '\'' || c == '"':
+			err = lx.lexString(c)
+		case c == '-' && isDigit(lx.peekByte(1)) && lx.expectsValue():
+			lx.lexWord()
+		case strings.IndexByte("(),|;", c) >= 0:
+			lx.emit(TokenPunct, lx.pos+1)
+		case strings.IndexByte("=!<>+-*/%~", c) >= 0:
+			err = lx.lexOperator()
+		default:
+			r, _ := utf8.DecodeRuneInString(lx.input[lx.pos:])
+			if !isWordRune(r) && r != '\\' {
+				return nil, lx.errorf("karakter teu dikenal '%c'", r)
+			}
+			lx.lexWord()
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lx.toks = append(lx.toks, Token{Kind: TokenEOF, Line: lx.line, Col: lx.col, Start: lx.pos, End: lx.pos})
+	return lx.toks, nil
+}
+
+func (lx *lexer) peekByte(n int) byte {
+	if lx.pos+n < len(lx.input) {
+		return lx.input[lx.pos+n]
+	}
+	return 0
+}
+
+func (lx *lexer) errorf(format string, args ...any) error {
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Line: lx.line, Col: lx.col}
+}
+
+// errorAt: kasalahan dina offset end (teu kurang ti lx.pos) tanpa maju.
+func (lx *lexer) errorAt(end int, format string, args ...any) error {
+	line, col := lx.line, lx.col
+	for _, r := range lx.input[lx.pos:end] {
+		if r == '\n' {
+			line++
+			col = 1
+		} else {
+			col++
+		}
+	}
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Line: line, Col: col}
+}
+
+// advance maju nepi ka offset end, bari ngitung baris & kolom.
+func (lx *lexer) advance(end int) {
+	for _, r := range lx.input[lx.pos:end] {
+		if r == '\n' {
+			lx.line++
+			lx.col = 1
+		} else {
+			lx.col++
+		}
+	}
+	lx.pos = end
+}
+
+func (lx *lexer) emit(kind TokenKind, end int) {
+	raw := lx.input[lx.pos:end]
+	lx.emitText(kind, raw, end)
+}
+
+func (lx *lexer) emitText(kind TokenKind, text string, end int) {
+	lx.toks = append(lx.toks, Token{
+		Kind:  kind,
+		Text:  text,
+		Raw:   lx.input[lx.pos:end],
+		Line:  lx.line,
+		Col:   lx.col,
+		Start: lx.pos,
+		End:   end,
+	})
+	lx.advance(end)
+}
+
+func (lx *lexer) skipSpace() {
+	end := lx.pos
+	for end < len(lx.input) {
+		r, size := utf8.DecodeRuneInString(lx.input[end:])
+		if !unicode.IsSpace(r) {
+			break
+		}
+		end += size
+	}
+	lx.advance(end)
+}
+
+func (lx *lexer) skipComment() {
+	end := strings.IndexByte(lx.input[lx.pos:], '\n')
+	if end < 0 {
+		lx.advance(len(lx.input))
+		return
+	}
+	lx.advance(lx.pos + end)
+}
+
+// expectsValue: '-' saméméh angka dianggap tanda négatif lamun token
+// saméméhna lain nilai (contona sanggeus operator, '(' atawa ',').
+func (lx *lexer) expectsValue() bool {
+	if len(lx.toks) == 0 {
+		return true
+	}
+	prev := lx.toks[len(lx.toks)-1]
+	switch prev.Kind {
+	case TokenOperator:
+		return true
+	case TokenPunct:
+		return prev.Text != ")"
+	}
+	return false
+}
+
+func (lx *lexer) lexString(quote byte) error {
+	text, end, err := lx.scanString(quote)
+	if err != nil {
+		return err
+	}
+	lx.emitText(TokenString, text, end)
+	return nil
+}
+
+// scanString maca string ti lx.pos tanpa maju; hasilna eusi nu geus
+// di-unescape jeung offset sanggeus tanda petik panutup.
+func (lx *lexer) scanString(quote byte) (string, int, error) {
+	var b strings.Builder
+	i := lx.pos + 1
+	for i < len(lx.input) {
+		c := lx.input[i]
+		switch {
+		case c == quote && quote == '\'' && i+1 < len(lx.input) && lx.input[i+1] == '\'':
+			b.WriteByte('\'')
+			i += 2
+		case c == quote:
+			return b.String(), i + 1, nil
+		case c == '\\':
+			if i+1 >= len(lx.input) {
+				i++
+				continue
+			}
+			switch e := lx.input[i+1]; e {
+			case 'n':
+				b.WriteByte('\n')
+			case 't':
+				b.WriteByte('\t')
+			case 'r':
+				b.WriteByte('\r')
+			case '\\', '\'', '"':
+				b.WriteByte(e)
+			default:
+				return "", 0, lx.errorAt(i, "escape teu dikenal '\\%c'", e)
+			}
+			i += 2
+		default:
+			b.WriteByte(c)
+			i++
+		}
+	}
+
+	return "", 0, lx.errorf("string teu ditutup")
+}
+
+func (lx *lexer) lexOperator() error {
+	two := lx.input[lx.pos:min(lx.pos+2, len(lx.input))]
+	switch two {
+	case "==", "!=", "<>", "<=", ">=", "!~":
+		lx.emit(TokenOperator, lx.pos+2)
+		return nil
+	}
+	if lx.input[lx.pos] == '!' {
+		return lx.errorf("operator teu lengkep '!'")
+	}
+	lx.emit(TokenOperator, lx.pos+1)
+	return nil
+}
+
+// lexWord maca kecap atawa angka (kaasup tanda '-' di hareup). Dina kecap,
+// '\' ngaloloskeun karakter saterusna (Asep\|Sunandar). '-' di tengah
+// kecap ngan kaasup dina tanggal (2024-01-10); jumlah-1 mah pangurangan.
+func (lx *lexer) lexWord() {
+	if n := dateLen(lx.input[lx.pos:]); n > 0 {
+		lx.emit(TokenWord, lx.pos+n)
+		return
+	}
+
+	var text strings.Builder
+	escaped := false
+
+	end := lx.pos
+	if lx.input[end] == '-' {
+		text.WriteByte('-')
+		end++
+	}
+	for end < len(lx.input) {
+		r, size := utf8.DecodeRuneInString(lx.input[end:])
+		if r == '\\' && end+1 < len(lx.input) {
+			e, esize := utf8.DecodeRuneInString(lx.input[end+1:])
+			text.WriteRune(unescapeRune(e))
+			escaped = true
+			end += 1 + esize
+			continue
+		}
+		if isWordRune(r) {
+			text.WriteRune(r)
+			end += size
+			continue
+		}
+		break
+	}
+
+	if !escaped && isNumber(text.String()) {
+		lx.emit(TokenNumber, end)
+		return
+	}
+	lx.emitText(TokenWord, text.String(), end)
+}
+
+// dateLen: panjang tanggal angka-angka-angka (2024-01-10) di hareup s, atawa
+// 0 lamun s lain dimimitian ku tanggal.
+func dateLen(s string) int {
+	i := 0
+	for part := 0; part < 3; part++ {
+		if part > 0 {
+			if i >= len(s) || s[i] != '-' {
+				return 0
+			}
+			i++
+		}
+		start := i
+		for i < len(s) && isDigit(s[i]) {
+			i++
+		}
+		if i == start {
+			return 0
+		}
+	}
+	if r, _ := utf8.DecodeRuneInString(s[i:]); i < len(s) && (isWordRune(r) || r == '-') {
+		return 0
+	}
+	return i
+}
+
+// atLegacyRow: token saméméhna "SIMPEN <tabel>" (atawa "JELASKEUN SIMPEN
+// <tabel>") sarta saterusna lain '(', NILAI atawa TINGALI.
+func (lx *lexer) atLegacyRow() bool {
+	n := len(lx.toks)
+	if n < 2 || n > 3 || !lx.toks[n-2].Is("SIMPEN") || lx.toks[n-1].Kind != TokenWord {
+		return false
+	}
+	if n == 3 && !lx.toks[0].Is("JELASKEUN") {
+		return false
+	}
+	if lx.input[lx.pos] == '(' {
+		return false
+	}
+	word := lx.input[lx.pos:]
+	if i := strings.IndexFunc(word, func(r rune) bool { return !isWordRune(r) }); i >= 0 {
+		word = word[:i]
+	}
+	return !strings.EqualFold(word, "NILAI") && !strings.EqualFold(word, "TINGALI")
+}
+
+// lexLegacyRow maca data SIMPEN format heubeul: unggal nilai nyaéta téks
+// nepi ka '|' salajengna, BALIKKEUN, koméntar " --", atawa tungtung
+// paréntah, jadi karakter saperti '&' atawa tanda petik tunggal (Jum'at)
+// teu dipariksa.
+// Nilai nu sakabéhna dina tanda petik ('a|b') dicokot eusina, sarta '\x'
+// tetep ngaloloskeun hiji karakter. Hasilna TokenString jeung tanda '|'.
+func (lx *lexer) lexLegacyRow() {
+	for {
+		lx.skipSpace()
+
+		c := lx.peekByte(0)
+		quoted := false
+		if c == '\'' || c == '"' {
+			if text, end, err := lx.scanString(c); err == nil {
+				stop := lx.legacyFieldEnd(end)
+				if strings.TrimSpace(lx.input[end:stop]) == "" {
+					lx.emitText(TokenString, text, end)
+					quoted = true
+				}
+			}
+		}
+		if !quoted {
+			stop := lx.legacyFieldEnd(lx.pos)
+			raw := strings.TrimRightFunc(lx.input[lx.pos:stop], unicode.IsSpace)
+			lx.emitText(TokenString, unescapeText(raw), lx.pos+len(raw))
+		}
+
+		lx.skipSpace()
+		if lx.peekByte(0) == '-' && lx.peekByte(1) == '-' {
+			lx.skipComment()
+			lx.skipSpace()
+		}
+		if lx.peekByte(0) != '|' {
+			return
+		}
+		lx.emit(TokenPunct, lx.pos+1)
+	}
+}
+
+// legacyFieldEnd: offset tungtung nilai format heubeul nu dimimitian dina
+// from: '|' nu teu diloloskeun, kecap BALIKKEUN, koméntar, atawa ';' di
+// tungtung.
+func (lx *lexer) legacyFieldEnd(from int) int {
+	in := lx.input
+	if isKeywordAt(in, from, "BALIKKEUN") || strings.HasPrefix(in[from:], "--") {
+		return from
+	}
+	for i := from; i < len(in); i++ {
+		switch c := in[i]; {
+		case c == '\\':
+			i++
+		case c == '|':
+			return i
+		case c == ';' && strings.TrimSpace(in[i+1:]) == "":
+			return i
+		case c == ' ' || c == '\t' || c == '\n' || c == '\r':
+			if isKeywordAt(in, i+1, "BALIKKEUN") || strings.HasPrefix(in[i+1:], "--") {
+				return i
+			}
+		}
+	}
+	return len(in)
+}
+
+// isKeywordAt: kecap keyword sagemblengna aya dina in[i:].
+func isKeywordAt(in string, i int, keyword string) bool {
+	end := i + len(keyword)
+	if end > len(in) || !strings.EqualFold(in[i:end], keyword) {
+		return false
+	}
+	r, _ := utf8.DecodeRuneInString(in[end:])
+	return end == len(in) || !isWordRune(r)
+}
+
+func unescapeText(s string) string {
+	if !strings.Contains(s, "\\") {
+		return s
+	}
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] == '\\' && i+1 < len(s) {
+			r, size := utf8.DecodeRuneInString(s[i+1:])
+			b.WriteRune(unescapeRune(r))
+			i += size
+			continue
+		}
+		b.WriteByte(s[i])
+	}
+	return b.String()
+}
+
+func unescapeRune(r rune) rune {
+	switch r {
+	case 'n':
+		return '\n'
+	case 't':
+		return '\t'
+	case 'r':
+		return '\r'
+	}
+	return r
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || strings.ContainsRune("_.:@", r)
+}
+
+func isDigit(c byte) bool { return c >= '0' && c <= '9' }
+
+func isNumber(s string) bool {
+	if s == "" || !(isDigit(s[0]) || (s[0] == '-' && len(s) > 1 && isDigit(s[1]))) {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err == nil
+}
@@ -0,0 +1,82 @@
+package parser
+
+import (
+	"errors"
+	"slices"
+	"testing"
+)
+
+// tokens mulangkeun "jenis:téks" unggal token, tanpa EOF.
+func tokens(t *testing.T, input string) []string {
+	t.Helper()
+	toks, err := Tokenize(input)
+	if err != nil {
+		t.Fatalf("%s: %v", input, err)
+	}
+	if last := toks[len(toks)-1]; last.Kind != TokenEOF {
+		t.Fatalf("%s: token pamungkas %v, lain EOF", input, last.Kind)
+	}
+	var out []string
+	for _, tok := range toks[:len(toks)-1] {
+		out = append(out, tok.Kind.String()+":"+tok.Text)
+	}
+	return out
+}
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		input string
+		want  []string
+	}{
+		{"TINGALI pegawai DIMANA nama = 'Asep Sunandar' SARENG umur>20 -- koméntar", []string{
+			"kecap:TINGALI", "kecap:pegawai", "kecap:DIMANA", "kecap:nama", "operator:=",
+			"string:Asep Sunandar", "kecap:SARENG", "kecap:umur", "operator:>", "angka:20",
+		}},
+		{`'Jum''at' "a\"b" 'baris\nanyar' 'C:\\data'`, []string{
+			"string:Jum'at", `string:a"b`, "string:baris\nanyar", `string:C:\data`,
+		}},
+		{"a<=b a<>b a!=b a~'^A' a!~b", []string{
+			"kecap:a", "operator:<=", "kecap:b", "kecap:a", "operator:<>", "kecap:b",
+			"kecap:a", "operator:!=", "kecap:b", "kecap:a", "operator:~", "string:^A",
+			"kecap:a", "operator:!~", "kecap:b",
+		}},
+		{"umur DI (20, 3.14, 1e6)", []string{
+			"kecap:umur", "kecap:DI", "tanda:(", "angka:20", "tanda:,", "angka:3.14",
+			"tanda:,", "angka:1e6", "tanda:)",
+		}},
+		{"masuk >= 2024-01-10 SARENG jumlah-1 < 5", []string{
+			"kecap:masuk", "operator:>=", "kecap:2024-01-10", "kecap:SARENG",
+			"kecap:jumlah", "operator:-", "angka:1", "operator:<", "angka:5",
+		}},
+		{"TINGALI p.nama TI pegawai;\n-- ngan koméntar", []string{
+			"kecap:TINGALI", "kecap:p.nama", "kecap:TI", "kecap:pegawai", "tanda:;",
+		}},
+	}
+	for _, tt := range tests {
+		if got := tokens(t, tt.input); !slices.Equal(got, tt.want) {
+			t.Errorf("%s:\nmeunang %q\nkuduna  %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestTokenizeErrorPosition(t *testing.T) {
+	tests := []struct {
+		input     string
+		line, col int
+	}{
+		{"TINGALI t DIMANA nama = 'Asep", 1, 25},
+		{"TINGALI t\nDIMANA nama = \"Asep", 2, 15},
+		{"TINGALI t DIMANA nama = 'a\\q'", 1, 27},
+	}
+	for _, tt := range tests {
+		_, err := Tokenize(tt.input)
+		var se *SyntaxError
+		if !errors.As(err, &se) {
+			t.Errorf("%q: kuduna SyntaxError, meunang %v", tt.input, err)
+			continue
+		}
+		if se.Line != tt.line || se.Col != tt.col {
+			t.Errorf("%q: posisi %d:%d, kuduna %d:%d (%v)", tt.input, se.Line, se.Col, tt.line, tt.col, err)
+		}
+	}
+}
@@ -2,235 +2,865 @@ package parser
 
 import (
 	"errors"
+	"fmt"
 	"strconv"
 	"strings"
 )
 
 func Parse(input string) (*Command, error) {
-	tokens := strings.Fields(input)
-	if len(tokens) < 2 {
+	toks, err := Tokenize(input)
+	if err != nil {
+		return nil, err
+	}
+
+	ts := &tokenStream{input: input, toks: toks}
+	if ts.atEnd() {
 		return nil, errors.New("query teu valid")
 	}
 
-	switch strings.ToUpper(tokens[0]) {
+	cmd, err := parseStatement(ts)
+	if err != nil {
+		return nil, err
+	}
+	if err := ts.expectEnd(); err != nil {
+		return nil, err
+	}
+	return cmd, nil
+}
+
+func parseStatement(ts *tokenStream) (*Command, error) {
+	switch strings.ToUpper(ts.peek().Text) {
+	case "JELASKEUN":
+		return parseExplain(ts)
+	case "MIMITIAN", "ANGGEUSAN", "BATALKEUN":
+		return parseTransaction(ts)
 	case "DAMEL":
-  		return parseCreate(tokens)
+		return parseCreate(ts)
 	case "SIMPEN":
-		return parseInsert(tokens)
+		return parseInsert(ts)
 	case "TINGALI":
-		return parseSelect(tokens)
+		return parseSelect(ts)
 	case "OMEAN":
-		return parseUpdate(tokens)
+		return parseUpdate(ts)
 	case "MICEUN":
-		return parseDelete(tokens)
+		return parseDelete(ts)
+	case "PICEUN":
+		return parseDrop(ts)
+	case "KOSONGKEUN":
+		return parseTruncate(ts)
+	case "GANTI":
+		return parseRename(ts)
 	default:
-		return nil, errors.New("paréntah teu dikenal")
+		return nil, ts.errorf("paréntah teu dikenal %s", ts.peek().quoted())
 	}
 }
 
-func parseCreate(tokens []string) (*Command, error) {
-    if len(tokens) < 3 {
-        return nil, errors.New("format: DAMEL <tabel> <definisi_kolom>")
-    }
-    return &Command{
-        Type: CmdCreate,
-        Table: tokens[1],
-        Data: tokens[2], 
-    }, nil
+// =======================
+// TOKEN STREAM
+// =======================
+
+// tokenStream nyaéta token-token hiji query nu keur diparse.
+type tokenStream struct {
+	input string
+	toks  []Token
+	pos   int
+}
+
+func (ts *tokenStream) peek() Token { return ts.toks[ts.pos] }
+
+func (ts *tokenStream) next() Token {
+	t := ts.toks[ts.pos]
+	if t.Kind != TokenEOF {
+		ts.pos++
+	}
+	return t
 }
 
-func parseUpdate(tokens []string) (*Command, error) {
-	if len(tokens) < 4 || strings.ToUpper(tokens[2]) != "JADI" {
-		return nil, errors.New("format OMEAN salah: OMEAN <table> JADI <col>=<val> DIMANA ...")
+// atEnd: geus beak (';' di tungtung diidinan).
+func (ts *tokenStream) atEnd() bool {
+	t := ts.peek()
+	if t.Kind == TokenPunct && t.Text == ";" {
+		return ts.toks[ts.pos+1].Kind == TokenEOF
 	}
+	return t.Kind == TokenEOF
+}
 
-	pairs := strings.Split(tokens[3], "=")
-	if len(pairs) != 2 {
-		return nil, errors.New("format update salah, gunakeun col=val")
+func (ts *tokenStream) expectEnd() error {
+	if !ts.atEnd() {
+		return ts.errorf("teu disangka %s", ts.peek().quoted())
 	}
+	return nil
+}
 
-	cmd := &Command{
-		Type:    CmdUpdate,
-		Table:   tokens[1],
-		Updates: map[string]string{pairs[0]: pairs[1]},
-		Where:   []Condition{},
+func (ts *tokenStream) acceptKeyword(keyword string) bool {
+	if ts.peek().Is(keyword) {
+		ts.pos++
+		return true
 	}
+	return false
+}
 
-	// Parse WHERE (dimana)
-	if len(tokens) > 4 {
-		if strings.ToUpper(tokens[4]) != "DIMANA" {
-			return nil, errors.New("kedah nganggo DIMANA")
+// accept ngaliwatan tanda / operator nu téksna sarua.
+func (ts *tokenStream) accept(text string) bool {
+	t := ts.peek()
+	if (t.Kind == TokenPunct || t.Kind == TokenOperator) && t.Text == text {
+		ts.pos++
+		return true
+	}
+	return false
+}
+
+func (ts *tokenStream) expect(text string) error {
+	if !ts.accept(text) {
+		return ts.errorf("kuduna '%s', lain %s", text, ts.peek().quoted())
+	}
+	return nil
+}
+
+// name maca ngaran (tabel, kolom, indeks).
+func (ts *tokenStream) name(what string) (string, error) {
+	t := ts.peek()
+	if t.Kind != TokenWord {
+		return "", ts.errorf("butuh ngaran %s", what)
+	}
+	ts.pos++
+	return t.Text, nil
+}
+
+// atUpsert: "ATAWA OMEAN" (SIMPEN ... ATAWA OMEAN), lain ATAWA dina DIMANA.
+func (ts *tokenStream) atUpsert() bool {
+	return ts.peek().Is("ATAWA") && ts.toks[ts.pos+1].Is("OMEAN")
+}
+
+// ifExists maca "LAMUN AYA" (opsional) dina PICEUN / KOSONGKEUN / GANTI NGARAN.
+func (ts *tokenStream) ifExists() bool {
+	if ts.peek().Is("LAMUN") && ts.toks[ts.pos+1].Is("AYA") {
+		ts.pos += 2
+		return true
+	}
+	return false
+}
+
+// value maca hiji nilai: string, angka, atawa kecap tanpa tanda petik.
+func (ts *tokenStream) value() (string, error) {
+	t := ts.peek()
+	switch t.Kind {
+	case TokenString, TokenNumber, TokenWord:
+		ts.pos++
+		return t.Text, nil
+	}
+	return "", ts.errorf("butuh nilai, lain %s", t.quoted())
+}
+
+func (ts *tokenStream) integer(keyword string) (int, error) {
+	t := ts.peek()
+	if t.Kind != TokenNumber {
+		return 0, ts.errorf("%s kudu angka", keyword)
+	}
+	n, err := strconv.Atoi(t.Text)
+	if err != nil {
+		return 0, ts.errorf("%s kudu angka", keyword)
+	}
+	ts.pos++
+	return n, nil
+}
+
+// field ngahijikeun token from nepi ka saméméh token ayeuna jadi hiji nilai,
+// kalawan spasi aslina: Asep Sunandar -> "Asep Sunandar". Hiji string
+// dicokot eusina; string di tengah téks séjén tetep jeung tanda petikna.
+func (ts *tokenStream) field(from int) string {
+	toks := ts.toks[from:ts.pos]
+	if len(toks) == 1 {
+		return toks[0].Text
+	}
+
+	var b strings.Builder
+	for i, t := range toks {
+		if i > 0 {
+			b.WriteString(ts.input[toks[i-1].End:t.Start])
 		}
-		whereCmd, err := parseWhere(tokens[5:]) // Reuse logic WHERE
-		if err != nil {
-			return nil, err
+		if t.Kind == TokenString {
+			b.WriteString(t.Raw)
+		} else {
+			b.WriteString(t.Text)
 		}
-		cmd.Where = whereCmd.Where
 	}
+	return b.String()
+}
+
+func (ts *tokenStream) errorf(format string, args ...any) error {
+	t := ts.peek()
+	return &SyntaxError{Msg: fmt.Sprintf(format, args...), Line: t.Line, Col: t.Col}
+}
+
+// usage ngaganti kasalahan sintaks ku conto format nu bener (posisi tetep).
+func usage(err error, format string) error {
+	var se *SyntaxError
+	if errors.As(err, &se) {
+		return &SyntaxError{Msg: se.Msg + "; format: " + format, Line: se.Line, Col: se.Col}
+	}
+	return err
+}
+
+// =======================
+// STATEMENTS
+// =======================
+
+// Sintaks: MIMITIAN | ANGGEUSAN | BATALKEUN
+func parseTransaction(ts *tokenStream) (*Command, error) {
+	switch strings.ToUpper(ts.next().Text) {
+	case "MIMITIAN":
+		return &Command{Type: CmdBegin}, nil
+	case "ANGGEUSAN":
+		return &Command{Type: CmdCommit}, nil
+	default:
+		return &Command{Type: CmdRollback}, nil
+	}
+}
 
+// Sintaks: JELASKEUN <query>
+func parseExplain(ts *tokenStream) (*Command, error) {
+	ts.next()
+	if ts.atEnd() {
+		return nil, ts.errorf("JELASKEUN butuh query")
+	}
+	cmd, err := parseStatement(ts)
+	if err != nil {
+		return nil, err
+	}
+	if cmd.Explain {
+		return nil, errors.New("JELASKEUN teu bisa dobel")
+	}
+	cmd.Explain = true
 	return cmd, nil
 }
 
-// Sintaks: MICEUN TI <table_name> DIMANA ...
-func parseDelete(tokens []string) (*Command, error) {
-	if len(tokens) < 3 || strings.ToUpper(tokens[1]) != "TI" {
-		return nil, errors.New("format MICEUN salah: MICEUN TI <table> DIMANA ...")
+// Sintaks: DAMEL <tabel> <kolom:TIPE>,<kolom:TIPE>,...
+// atawa:   DAMEL <tabel> SALAKU TINGALI ... (schema dicokot tina hasilna)
+func parseCreate(ts *tokenStream) (*Command, error) {
+	const format = "DAMEL <tabel> <definisi_kolom>"
+	ts.next()
+
+	if ts.acceptKeyword("INDEKS") {
+		return parseCreateIndex(ts)
 	}
 
-	cmd := &Command{
-		Type:  CmdDelete,
-		Table: tokens[2],
-		Where: []Condition{},
+	table, err := ts.name("tabel")
+	if err != nil {
+		return nil, usage(err, format)
 	}
 
-	if len(tokens) > 3 {
-		if strings.ToUpper(tokens[3]) != "DIMANA" {
-			return nil, errors.New("kedah nganggo DIMANA")
+	if ts.acceptKeyword("SALAKU") {
+		if !ts.peek().Is("TINGALI") {
+			return nil, usage(ts.errorf("butuh TINGALI, lain %s", ts.peek().quoted()), "DAMEL <tabel> SALAKU TINGALI ...")
 		}
-		whereCmd, err := parseWhere(tokens[4:])
+		src, err := parseSelect(ts)
 		if err != nil {
 			return nil, err
 		}
-		cmd.Where = whereCmd.Where
+		return &Command{Type: CmdCreate, Table: table, Source: src}, nil
+	}
+
+	// definisi kolom disusun deui tina token: spasi di sabudeureun tanda
+	// dipiceun ("gender:ENUM(L, P)" -> "gender:ENUM(L,P)"), tapi spasi di
+	// antara dua nilai tetep (nama:STRING=Asep Sunandar), jadi kecap
+	// kaleuwihan (id:INT nama:STRING) jadi kasalahan tipe, lain dihijikeun.
+	var b strings.Builder
+	var prev Token
+	for i := 0; !ts.atEnd(); i++ {
+		t := ts.next()
+		if i > 0 && isValueToken(prev) && isValueToken(t) {
+			b.WriteString(ts.input[prev.End:t.Start])
+		}
+		b.WriteString(t.Raw)
+		prev = t
 	}
+	if b.Len() == 0 {
+		return nil, usage(ts.errorf("butuh definisi kolom"), format)
+	}
+
+	return &Command{
+		Type:  CmdCreate,
+		Table: table,
+		Data:  b.String(),
+	}, nil
+}
+
+func isValueToken(t Token) bool {
+	return t.Kind == TokenWord || t.Kind == TokenNumber || t.Kind == TokenString
+}
+
+// Sintaks: DAMEL INDEKS <ngaran> DINA <table>(<kolom>)
+func parseCreateIndex(ts *tokenStream) (*Command, error) {
+	const format = "DAMEL INDEKS <ngaran> DINA <tabel>(<kolom>)"
 
+	cmd := &Command{Type: CmdCreateIndex}
+	var err error
+
+	if cmd.Index, err = ts.name("indeks"); err != nil {
+		return nil, usage(err, format)
+	}
+	if !ts.acceptKeyword("DINA") {
+		return nil, usage(ts.errorf("butuh DINA"), format)
+	}
+	if cmd.Table, err = ts.name("tabel"); err != nil {
+		return nil, usage(err, format)
+	}
+	if err := ts.expect("("); err != nil {
+		return nil, usage(err, format)
+	}
+	if cmd.Column, err = ts.name("kolom"); err != nil {
+		return nil, usage(err, format)
+	}
+	if err := ts.expect(")"); err != nil {
+		return nil, usage(err, format)
+	}
 	return cmd, nil
 }
 
+// Sintaks: OMEAN <tabel> JADI <kolom>=<nilai>, ... (DIMANA ... | SADAYANA)
+func parseUpdate(ts *tokenStream) (*Command, error) {
+	const format = "OMEAN <table> JADI <col>=<nilai>, ... (DIMANA ... | SADAYANA)"
+	ts.next()
 
-func parseInsert(tokens []string) (*Command, error) {
-	if len(tokens) < 3 {
-		return nil, errors.New("format simpen salah: simpen <table> <data>")
+	table, err := ts.name("tabel")
+	if err != nil {
+		return nil, usage(err, format)
 	}
-	return &Command{
-		Type:  CmdInsert,
-		Table: tokens[1],
-		Data:  tokens[2],
-	}, nil
+	if !ts.acceptKeyword("JADI") {
+		return nil, usage(ts.errorf("butuh JADI"), format)
+	}
+
+	cmd := &Command{Type: CmdUpdate, Table: table}
+	seen := make(map[string]bool)
+	for {
+		col, err := ts.name("kolom")
+		if err != nil {
+			return nil, usage(err, format)
+		}
+		if seen[col] {
+			return nil, ts.errorf("kolom '%s' diomean leuwih ti sakali", col)
+		}
+		seen[col] = true
+
+		if err := ts.expect("="); err != nil {
+			return nil, usage(err, format)
+		}
+		val, err := parseValue(ts)
+		if err != nil {
+			return nil, usage(err, format)
+		}
+		cmd.Updates = append(cmd.Updates, Assignment{Column: col, Value: val})
+
+		if !ts.accept(",") {
+			break
+		}
+	}
+
+	if err := parseFilter(ts, cmd); err != nil {
+		return nil, err
+	}
+	return cmd, parseReturning(ts, cmd)
+}
+
+// Sintaks: MICEUN TI <table_name> (DIMANA ... | SADAYANA)
+func parseDelete(ts *tokenStream) (*Command, error) {
+	const format = "MICEUN TI <table> (DIMANA ... | SADAYANA)"
+	ts.next()
+
+	if !ts.acceptKeyword("TI") {
+		return nil, usage(ts.errorf("butuh TI"), format)
+	}
+	table, err := ts.name("tabel")
+	if err != nil {
+		return nil, usage(err, format)
+	}
+
+	cmd := &Command{
+		Type:  CmdDelete,
+		Table: table,
+	}
+
+	if err := parseFilter(ts, cmd); err != nil {
+		return nil, err
+	}
+	return cmd, parseReturning(ts, cmd)
+}
+
+// Sintaks: PICEUN TABEL [LAMUN AYA] <tabel>
+// atawa:   PICEUN DATABASE [LAMUN AYA] <database>
+func parseDrop(ts *tokenStream) (*Command, error) {
+	const format = "PICEUN (TABEL | DATABASE) [LAMUN AYA] <ngaran>"
+	ts.next()
+
+	cmd := &Command{}
+	var err error
+	switch {
+	case ts.acceptKeyword("TABEL"):
+		cmd.Type = CmdDropTable
+		cmd.IfExists = ts.ifExists()
+		cmd.Table, err = ts.name("tabel")
+	case ts.acceptKeyword("DATABASE"):
+		cmd.Type = CmdDropDatabase
+		cmd.IfExists = ts.ifExists()
+		cmd.Database, err = ts.name("database")
+	default:
+		return nil, usage(ts.errorf("butuh TABEL atawa DATABASE, lain %s", ts.peek().quoted()), format)
+	}
+	if err != nil {
+		return nil, usage(err, format)
+	}
+	return cmd, nil
+}
+
+// Sintaks: KOSONGKEUN [TABEL] [LAMUN AYA] <tabel>
+func parseTruncate(ts *tokenStream) (*Command, error) {
+	const format = "KOSONGKEUN [TABEL] [LAMUN AYA] <tabel>"
+	ts.next()
+	ts.acceptKeyword("TABEL")
+
+	cmd := &Command{Type: CmdTruncate, IfExists: ts.ifExists()}
+	var err error
+	if cmd.Table, err = ts.name("tabel"); err != nil {
+		return nil, usage(err, format)
+	}
+	return cmd, nil
+}
+
+// Sintaks: GANTI NGARAN (TABEL | DATABASE) [LAMUN AYA] <lama> JADI <anyar>
+func parseRename(ts *tokenStream) (*Command, error) {
+	const format = "GANTI NGARAN (TABEL | DATABASE) [LAMUN AYA] <lama> JADI <anyar>"
+	ts.next()
+
+	if !ts.acceptKeyword("NGARAN") {
+		return nil, usage(ts.errorf("butuh NGARAN"), format)
+	}
+
+	cmd := &Command{}
+	var err error
+	switch {
+	case ts.acceptKeyword("TABEL"):
+		cmd.Type = CmdRenameTable
+		cmd.IfExists = ts.ifExists()
+		cmd.Table, err = ts.name("tabel")
+	case ts.acceptKeyword("DATABASE"):
+		cmd.Type = CmdRenameDatabase
+		cmd.IfExists = ts.ifExists()
+		cmd.Database, err = ts.name("database")
+	default:
+		return nil, usage(ts.errorf("butuh TABEL atawa DATABASE, lain %s", ts.peek().quoted()), format)
+	}
+	if err != nil {
+		return nil, usage(err, format)
+	}
+
+	if !ts.acceptKeyword("JADI") {
+		return nil, usage(ts.errorf("butuh JADI"), format)
+	}
+	if cmd.NewName, err = ts.name("anyar"); err != nil {
+		return nil, usage(err, format)
+	}
+	return cmd, nil
 }
 
+// parseFilter maca "DIMANA ..." atawa "SADAYANA" di tungtung OMEAN / MICEUN.
+// Lamun duanana teu aya, Where nil jeung All false; executor nu nolak.
+func parseFilter(ts *tokenStream, cmd *Command) error {
+	if ts.acceptKeyword("SADAYANA") {
+		cmd.All = true
+		return nil
+	}
+	if ts.atEnd() || ts.peek().Is("BALIKKEUN") {
+		return nil
+	}
+	if !ts.acceptKeyword("DIMANA") {
+		return ts.errorf("kedah nganggo DIMANA atawa SADAYANA")
+	}
+
+	var err error
+	cmd.Where, err = parseWhere(ts)
+	return err
+}
 
-func parseSelect(tokens []string) (*Command, error) {
-	if len(tokens) < 2 {
-		return nil, errors.New("format TINGALI salah, minimal: TINGALI <tabel>")
+// Sintaks: SIMPEN <tabel> <nilai>|<nilai>|...
+//
+// Nilai nu ngandung '|' atawa spasi di tungtung ditulis dina tanda petik;
+// nilai tanpa tanda petik dicokot sakumaha ditulis (Asep Sunandar).
+//
+// Atawa: SIMPEN <tabel> [(<kolom>, ...)] NILAI (<nilai>, ...), (<nilai>, ...)
+// pikeun sababaraha baris sakaligus; kolom nu teu disebut dieusian ku
+// default-na. NILAI bisa diganti ku TINGALI ... pikeun nyimpen hasil query.
+//
+// Ditambah ATAWA OMEAN DUMASAR <kolom>, ... [JADI <kolom>, ...]: baris nu
+// koncina geus aya diomean (kolom JADI), nu can aya disimpen.
+func parseInsert(ts *tokenStream) (*Command, error) {
+	const format = "SIMPEN <table> <data>"
+	ts.next()
+
+	table, err := ts.name("tabel")
+	if err != nil {
+		return nil, usage(err, format)
+	}
+	if ts.atEnd() {
+		return nil, usage(ts.errorf("butuh data"), format)
+	}
+	if hasValuesClause(ts) {
+		return parseInsertValues(ts, table)
+	}
+
+	var values []string
+	for {
+		from := ts.pos
+		for !ts.atEnd() && !(ts.peek().Kind == TokenPunct && ts.peek().Text == "|") && !ts.peek().Is("BALIKKEUN") {
+			ts.next()
+		}
+
+		values = append(values, ts.field(from))
+
+		if !ts.accept("|") {
+			break
+		}
 	}
 
 	cmd := &Command{
-		Type:  CmdSelect,
-		Table: tokens[1],
-		Where: []Condition{},
-		Limit: -1, 
+		Type:  CmdInsert,
+		Table: table,
+		Rows:  [][]string{values},
 	}
+	return cmd, parseReturning(ts, cmd)
+}
 
-	idx := 2
+// hasValuesClause: naha nu salajengna téh "NILAI ..." / "TINGALI ...", atawa
+// "(...) NILAI ...". Lamun lain, data dibaca ku format heubeul (a|b|c).
+func hasValuesClause(ts *tokenStream) bool {
+	if isValuesKeyword(ts.peek()) {
+		return true
+	}
+	if ts.peek().Kind != TokenPunct || ts.peek().Text != "(" {
+		return false
+	}
+	for i := ts.pos + 1; i < len(ts.toks); i++ {
+		t := ts.toks[i]
+		if t.Kind == TokenEOF || (t.Kind == TokenPunct && t.Text == "(") {
+			return false
+		}
+		if t.Kind == TokenPunct && t.Text == ")" {
+			return isValuesKeyword(ts.toks[i+1])
+		}
+	}
+	return false
+}
+
+func isValuesKeyword(t Token) bool {
+	return t.Is("NILAI") || t.Is("TINGALI")
+}
 
+func parseInsertValues(ts *tokenStream, table string) (*Command, error) {
+	const format = "SIMPEN <tabel> (<kolom>, ...) NILAI (<nilai>, ...), ..."
+	cmd := &Command{Type: CmdInsert, Table: table}
 
-	if idx < len(tokens) && strings.ToUpper(tokens[idx]) == "DIMANA" {
-		endIdx := len(tokens)
-		
-		for i := idx + 1; i < len(tokens); i++ {
-			kw := strings.ToUpper(tokens[i])
-			if kw == "RUNTUYKEUN" || kw == "SAKADAR" || kw == "LIWATAN" {
-				endIdx = i
+	if ts.accept("(") {
+		for {
+			col, err := ts.name("kolom")
+			if err != nil {
+				return nil, usage(err, format)
+			}
+			cmd.Columns = append(cmd.Columns, col)
+			if !ts.accept(",") {
 				break
 			}
 		}
-
-		whereCmd, err := parseWhere(tokens[idx+1 : endIdx])
+		if err := ts.expect(")"); err != nil {
+			return nil, usage(err, format)
+		}
+	}
+	if ts.peek().Is("TINGALI") {
+		src, err := parseSelect(ts)
 		if err != nil {
 			return nil, err
 		}
-		cmd.Where = whereCmd.Where
-		
-		idx = endIdx
+		cmd.Source = src
+		if err := parseUpsert(ts, cmd); err != nil {
+			return nil, err
+		}
+		return cmd, parseReturning(ts, cmd)
 	}
+	ts.next() // NILAI
 
-	if idx < len(tokens) && strings.ToUpper(tokens[idx]) == "RUNTUYKEUN" {
-		if idx+1 >= len(tokens) {
-			return nil, errors.New("RUNTUYKEUN butuh ngaran kolom")
+	for {
+		if err := ts.expect("("); err != nil {
+			return nil, usage(err, format)
 		}
-		
-		cmd.OrderBy = tokens[idx+1]
-		idx += 2 
-
-		if idx < len(tokens) {
-			mode := strings.ToUpper(tokens[idx])
-			if mode == "TI_LUHUR" || mode == "TURUN" { 
-				cmd.OrderDesc = true
-				idx++
-			} else if mode == "TI_HANDAP" || mode == "NAEK" {
-				cmd.OrderDesc = false
-				idx++
+		var row []string
+		var nulls []bool
+		for {
+			v, null, err := insertValue(ts)
+			if err != nil {
+				return nil, usage(err, format)
 			}
+			row = append(row, v)
+			nulls = append(nulls, null)
+			if !ts.accept(",") {
+				break
+			}
+		}
+		if err := ts.expect(")"); err != nil {
+			return nil, usage(err, format)
+		}
+		cmd.Rows = append(cmd.Rows, row)
+		cmd.Nulls = append(cmd.Nulls, nulls)
+		if !ts.accept(",") {
+			break
 		}
 	}
+	if err := parseUpsert(ts, cmd); err != nil {
+		return nil, err
+	}
+	return cmd, parseReturning(ts, cmd)
+}
+
+// parseReturning: [BALIKKEUN <kolom>, ... | *] di tungtung SIMPEN / OMEAN /
+// MICEUN.
+func parseReturning(ts *tokenStream, cmd *Command) error {
+	if !ts.acceptKeyword("BALIKKEUN") {
+		return nil
+	}
+	items, err := parseSelectList(ts)
+	if err != nil {
+		return usage(err, "BALIKKEUN <kolom>, ... | *")
+	}
+	cmd.Returning = items
+	return nil
+}
+
+// parseUpsert: [ATAWA OMEAN DUMASAR <kolom>, ... [JADI <kolom>, ...]]
+func parseUpsert(ts *tokenStream, cmd *Command) error {
+	const format = "SIMPEN ... ATAWA OMEAN DUMASAR <kolom_konci>, ... [JADI <kolom>, ...]"
+	if !ts.atUpsert() {
+		return nil
+	}
+	ts.next()
+	ts.next()
 
-	if idx < len(tokens) && strings.ToUpper(tokens[idx]) == "SAKADAR" {
-		if idx+1 >= len(tokens) {
-			return nil, errors.New("SAKADAR butuh angka")
+	if !ts.acceptKeyword("DUMASAR") {
+		return usage(ts.errorf("butuh DUMASAR, lain %s", ts.peek().quoted()), format)
+	}
+	var err error
+	if cmd.Conflict, err = nameList(ts, "kolom konci (DUMASAR)"); err != nil {
+		return usage(err, format)
+	}
+	if ts.acceptKeyword("JADI") {
+		if cmd.Overwrite, err = nameList(ts, "kolom (JADI)"); err != nil {
+			return usage(err, format)
 		}
-		
-		limit, err := strconv.Atoi(tokens[idx+1])
+	}
+	return nil
+}
+
+// nameList maca <ngaran>, <ngaran>, ...
+func nameList(ts *tokenStream, what string) ([]string, error) {
+	var names []string
+	for {
+		name, err := ts.name(what)
 		if err != nil {
-			return nil, errors.New("SAKADAR kudu angka")
+			return nil, err
+		}
+		names = append(names, name)
+		if !ts.accept(",") {
+			return names, nil
 		}
-		
-		cmd.Limit = limit
-		idx += 2
 	}
+}
 
-	if idx < len(tokens) && strings.ToUpper(tokens[idx]) == "LIWATAN" {
-		if idx+1 >= len(tokens) {
-			return nil, errors.New("LIWATAN butuh angka")
+// insertValue maca hiji nilai dina NILAI (...): string, angka (kaasup
+// négatif), kecap, atawa KOSONG (null) tanpa tanda petik.
+func insertValue(ts *tokenStream) (string, bool, error) {
+	if ts.accept("-") {
+		if t := ts.peek(); t.Kind == TokenNumber {
+			ts.next()
+			return "-" + t.Text, false, nil
 		}
-		
-		offset, err := strconv.Atoi(tokens[idx+1])
-		if err != nil {
-			return nil, errors.New("LIWATAN kudu angka")
+		return "", false, ts.errorf("butuh angka saatos '-', lain %s", ts.peek().quoted())
+	}
+	if ts.peek().Is("KOSONG") {
+		ts.next()
+		return "", true, nil
+	}
+	v, err := ts.value()
+	return v, false, err
+}
+
+// Sintaks: TINGALI [<kolom> [SALAKU <alias>], ... TI] <tabel> [SALAKU <alias>]
+// [GABUNG [KENCA] <tabel> [SALAKU <alias>] DINA <kondisi>]... [DIMANA ...]
+// [KUMPULKEUN DUMASAR <kolom>, ...] [ANU ...]
+// [RUNTUYKEUN <kolom> [TURUN|NAEK], ...] [SAKADAR n] [LIWATAN n]
+func parseSelect(ts *tokenStream) (*Command, error) {
+	const format = "TINGALI [<kolom>, ... TI] <tabel>"
+	ts.next()
+
+	fields, err := parseSelectList(ts)
+	if err != nil {
+		return nil, usage(err, format)
+	}
+
+	var table, alias string
+	switch {
+	case ts.acceptKeyword("TI"):
+		if table, err = ts.name("tabel"); err != nil {
+			return nil, usage(err, format)
 		}
-		
-		cmd.Offset = offset
-		idx += 2
+	case len(fields) == 1 && isIdent(fields[0].Expr):
+		// TINGALI <tabel> [SALAKU <alias>]
+		table, alias, fields = fields[0].Expr.(*Ident).Name, fields[0].Alias, nil
+	default:
+		return nil, usage(ts.errorf("butuh TI <tabel>"), format)
 	}
 
-	return cmd, nil
-}
+	// TINGALI * TI <tabel> sarua jeung TINGALI <tabel>
+	if len(fields) == 1 && fields[0].Star {
+		fields = nil
+	}
+
+	cmd := &Command{
+		Type:       CmdSelect,
+		Table:      table,
+		TableAlias: alias,
+		Fields:     fields,
+		Limit:      -1,
+	}
 
+	if alias == "" && ts.acceptKeyword("SALAKU") {
+		if cmd.TableAlias, err = ts.name("alias tabel (SALAKU)"); err != nil {
+			return nil, err
+		}
+	}
 
-func parseWhere(tokens []string) (*Command, error) {
-	cmd := &Command{Where: []Condition{}}
-	remaining := tokens
-	
-	for len(remaining) >= 3 {
-		cond := Condition{
-			Field:    remaining[0],
-			Operator: remaining[1],
-			Value:    remaining[2],
-			LogicOp:  "",
+	for ts.peek().Is("GABUNG") {
+		join, err := parseJoin(ts)
+		if err != nil {
+			return nil, err
 		}
+		cmd.Joins = append(cmd.Joins, join)
+	}
 
-		if strings.ToUpper(cond.Operator) == "JIGA" {
-			cond.Value = strings.Trim(cond.Value, "'\"")
+	if ts.acceptKeyword("DIMANA") {
+		if cmd.Where, err = parseWhere(ts); err != nil {
+			return nil, err
 		}
+	}
 
-		if len(remaining) > 3 {
-			rawLogic := strings.ToUpper(remaining[3])
-			
-			if rawLogic == "sareng" || rawLogic == "SARENG" {
-				cond.LogicOp = "SARENG"
-				remaining = remaining[4:] 
-			} else if rawLogic == "atawa" || rawLogic == "ATAWA" {
-				cond.LogicOp = "ATAWA"
-				remaining = remaining[4:]
-			} else {
-				remaining = nil
+	if ts.acceptKeyword("KUMPULKEUN") {
+		if !ts.acceptKeyword("DUMASAR") {
+			return nil, usage(ts.errorf("butuh DUMASAR"), "KUMPULKEUN DUMASAR <kolom>, ...")
+		}
+		for {
+			col, err := ts.name("kolom (KUMPULKEUN DUMASAR)")
+			if err != nil {
+				return nil, err
+			}
+			cmd.GroupBy = append(cmd.GroupBy, col)
+			if !ts.accept(",") {
+				break
 			}
-		} else {
-			remaining = nil
 		}
+	}
 
-		cmd.Where = append(cmd.Where, cond)
+	if ts.acceptKeyword("ANU") {
+		if cmd.Having, err = parseWhere(ts); err != nil {
+			return nil, err
+		}
+	}
+
+	if ts.acceptKeyword("RUNTUYKEUN") {
+		for {
+			var item OrderItem
+			if item.Column, err = ts.name("kolom (RUNTUYKEUN)"); err != nil {
+				return nil, err
+			}
+
+			switch {
+			case ts.acceptKeyword("TI_LUHUR"), ts.acceptKeyword("TURUN"):
+				item.Desc = true
+			case ts.acceptKeyword("TI_HANDAP"), ts.acceptKeyword("NAEK"):
+				item.Desc = false
+			}
+			cmd.OrderBy = append(cmd.OrderBy, item)
+
+			if !ts.accept(",") {
+				break
+			}
+		}
+	}
+
+	if ts.acceptKeyword("SAKADAR") {
+		if cmd.Limit, err = ts.integer("SAKADAR"); err != nil {
+			return nil, err
+		}
+	}
+
+	if ts.acceptKeyword("LIWATAN") {
+		if cmd.Offset, err = ts.integer("LIWATAN"); err != nil {
+			return nil, err
+		}
 	}
 
 	return cmd, nil
-}
\ No newline at end of file
+}
+
+// parseSelectList: <éksprési> [SALAKU <alias>], ... atawa *.
+func parseSelectList(ts *tokenStream) ([]SelectItem, error) {
+	var items []SelectItem
+	for {
+		var item SelectItem
+		if ts.accept("*") {
+			item.Star = true
+		} else {
+			x, err := parseValue(ts)
+			if err != nil {
+				return nil, err
+			}
+			item.Expr = x
+			if ts.acceptKeyword("SALAKU") {
+				if item.Alias, err = ts.name("alias (SALAKU)"); err != nil {
+					return nil, err
+				}
+			}
+		}
+		items = append(items, item)
+
+		if !ts.accept(",") {
+			return items, nil
+		}
+	}
+}
+
+func isIdent(e Expr) bool {
+	_, ok := e.(*Ident)
+	return ok
+}
+
+// Sintaks: GABUNG [KENCA] <tabel> [SALAKU <alias>] DINA <kondisi>
+func parseJoin(ts *tokenStream) (Join, error) {
+	const format = "GABUNG [KENCA] <tabel> DINA <tabel>.<kolom> = <tabel>.<kolom>"
+	ts.next()
+
+	var j Join
+	j.Left = ts.acceptKeyword("KENCA")
+
+	var err error
+	if j.Table, err = ts.name("tabel (GABUNG)"); err != nil {
+		return Join{}, usage(err, format)
+	}
+	if ts.acceptKeyword("SALAKU") {
+		if j.Alias, err = ts.name("alias tabel (SALAKU)"); err != nil {
+			return Join{}, err
+		}
+	}
+	if !ts.acceptKeyword("DINA") {
+		return Join{}, usage(ts.errorf("butuh DINA"), format)
+	}
+	if j.On, err = parseWhere(ts); err != nil {
+		return Join{}, err
+	}
+	return j, nil
+}
@@ -0,0 +1,99 @@
+package parser
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseLegacyInsert(t *testing.T) {
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{"SIMPEN t 1|Kopi & Teh|5000", []string{"1", "Kopi & Teh", "5000"}},
+		{"SIMPEN t 1|Jum'at|5000", []string{"1", "Jum'at", "5000"}},
+		{"SIMPEN t 1|Asep Sunandar|a=b<c>", []string{"1", "Asep Sunandar", "a=b<c>"}},
+		{"SIMPEN t 1|'Siti | Aminah'|x", []string{"1", "Siti | Aminah", "x"}},
+		{`SIMPEN t 1|Asep\|Sunandar`, []string{"1", "Asep|Sunandar"}},
+		{"SIMPEN t 1||  3  |", []string{"1", "", "3", ""}},
+		{"SIMPEN t 1|2023-02-20 -- koméntar", []string{"1", "2023-02-20"}},
+		{"SIMPEN t 1|a--b;", []string{"1", "a--b"}},
+		{"simpen t 'a' b|c", []string{"'a' b", "c"}},
+		{"SIMPEN t 1|'abc", []string{"1", "'abc"}},
+	}
+	for _, tt := range tests {
+		cmd, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if len(cmd.Rows) != 1 || !slices.Equal(cmd.Rows[0], tt.want) {
+			t.Errorf("%s: meunang %q, kuduna %q", tt.query, cmd.Rows, tt.want)
+		}
+	}
+}
+
+func TestParseLegacyInsertReturning(t *testing.T) {
+	cmd, err := Parse("SIMPEN t 1|Kopi & Teh BALIKKEUN id")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !slices.Equal(cmd.Rows[0], []string{"1", "Kopi & Teh"}) {
+		t.Errorf("baris %q", cmd.Rows[0])
+	}
+	if len(cmd.Returning) != 1 || cmd.Returning[0].Name() != "id" {
+		t.Errorf("BALIKKEUN %v", cmd.Returning)
+	}
+
+	cmd, err = Parse("JELASKEUN SIMPEN t 1|Jum'at")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !cmd.Explain || !slices.Equal(cmd.Rows[0], []string{"1", "Jum'at"}) {
+		t.Errorf("JELASKEUN: %v %q", cmd.Explain, cmd.Rows)
+	}
+}
+
+func TestParseMinusBetweenWords(t *testing.T) {
+	cmd, err := Parse("OMEAN stok JADI jumlah = jumlah-1 SADAYANA")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, ok := cmd.Updates[0].Value.(*Binary)
+	if !ok || b.Op != "-" {
+		t.Fatalf("kuduna pangurangan, meunang %#v", cmd.Updates[0].Value)
+	}
+
+	cmd, err = Parse("TINGALI t DIMANA masuk = 2024-01-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := cmd.Where.String(); got != "masuk = 2024-01-10" {
+		t.Errorf("tanggal: %s", got)
+	}
+	if _, ok := cmd.Where.(*Binary).Right.(*Ident); !ok {
+		t.Errorf("tanggal kuduna hiji kecap, meunang %#v", cmd.Where.(*Binary).Right)
+	}
+}
+
+func TestParseCreateKeepsSpacesInValues(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{"DAMEL t nama:STRING=Asep Sunandar", "nama:STRING=Asep Sunandar"},
+		{"DAMEL t id:INT, gender:ENUM(L, P)", "id:INT,gender:ENUM(L,P)"},
+		{"DAMEL t ket:STRING = 'ti sistem', n:INT=-5", "ket:STRING='ti sistem',n:INT=-5"},
+		{"DAMEL t id:INT nama:STRING", "id:INT nama:STRING"},
+	}
+	for _, tt := range tests {
+		cmd, err := Parse(tt.query)
+		if err != nil {
+			t.Errorf("%s: %v", tt.query, err)
+			continue
+		}
+		if cmd.Data != tt.want {
+			t.Errorf("%s: meunang %q, kuduna %q", tt.query, cmd.Data, tt.want)
+		}
+	}
+}
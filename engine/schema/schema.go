@@ -1,21 +1,25 @@
 package schema
 
 import (
+	"cmp"
 	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strconv"
 	"strings"
 	"time" 
 
 	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
 )
 
 type Column struct {
 	Name string
 	Type string   
 	Args []string 
+	Default string // nilai pikeun SIMPEN nu teu nyebut kolom ieu ("" = kosong)
 }
 
 type Definition struct {
@@ -25,7 +29,10 @@ type Definition struct {
 
 
 func Create(database, table string, fieldsRaw []string, perms map[string][]string) error {
-	path := filepath.Join(config.DataDir, "db_"+database, table+".schema")
+	path := schemaPath(database, table)
+	if _, err := os.Stat(path); err == nil {
+		return fmt.Errorf("tabel '%s' geus aya", table)
+	}
 
 	var headerParts []string
 	
@@ -36,13 +43,26 @@ func Create(database, table string, fieldsRaw []string, perms map[string][]strin
 		}
 
 		colName := parts[0]
-		fullType := strings.ToUpper(parts[1]) 
+		typePart, def, hasDefault := strings.Cut(parts[1], "=")
+		fullType := strings.ToUpper(typePart) 
 		baseType := parseBaseType(fullType)
 		if !isValidType(baseType) {
 			return errors.New("tipe data teu didukung: " + baseType)
 		}
 
-		headerParts = append(headerParts, colName+":"+fullType)
+		header := colName+":"+fullType
+		if hasDefault {
+			def = unquote(strings.TrimSpace(def))
+			if strings.ContainsAny(def, "|\n") {
+				return fmt.Errorf("default kolom '%s' teu meunang ngandung '|' atawa baris anyar", colName)
+			}
+			base, args := parseTypeAndArgs(fullType)
+			if err := (Column{Name: colName, Type: base, Args: args}).Validate(def); err != nil {
+				return fmt.Errorf("default teu valid: %w", err)
+			}
+			header += "=" + def
+		}
+		headerParts = append(headerParts, header)
 	}
 
 	content := strings.Join(headerParts, "|") + "\n"
@@ -51,11 +71,68 @@ func Create(database, table string, fieldsRaw []string, perms map[string][]strin
 		content += fmt.Sprintf("%s=%s\n", role, strings.Join(actions, ","))
 	}
 
-	return os.WriteFile(path, []byte(content), 0644)
+	return fsutil.WriteFile(path, []byte(content), 0644)
+}
+
+func schemaPath(database, table string) string {
+	return filepath.Join(config.DataDir, "db_"+database, table+".schema")
+}
+
+// Exists: tabel geus boga schema.
+func Exists(database, table string) bool {
+	_, err := os.Stat(schemaPath(database, table))
+	return err == nil
+}
+
+// Drop miceun schema tabel (baris & indeks diurus ku storage.Engine.Drop).
+// Schema nu geus euweuh lain error, jadi PICEUN nu gagal di tengah bisa
+// diulang.
+func Drop(database, table string) error {
+	if err := os.Remove(schemaPath(database, table)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return fsutil.SyncDir(filepath.Join(config.DataDir, "db_"+database))
+}
+
+// Rename mindahkeun schema tabel ka ngaran (jeung database) anyar.
+func Rename(database, table, newDatabase, newTable string) error {
+	if Exists(newDatabase, newTable) {
+		return fmt.Errorf("tabel '%s' geus aya", newTable)
+	}
+	if !Exists(database, table) {
+		return errors.New("table teu kapanggih")
+	}
+	if err := os.Rename(schemaPath(database, table), schemaPath(newDatabase, newTable)); err != nil {
+		return err
+	}
+	if err := fsutil.SyncDir(filepath.Join(config.DataDir, "db_"+newDatabase)); err != nil {
+		return err
+	}
+	if newDatabase != database {
+		return fsutil.SyncDir(filepath.Join(config.DataDir, "db_"+database))
+	}
+	return nil
+}
+
+// Tables mulangkeun ngaran sakabéh tabel dina database, diurutkeun.
+func Tables(database string) ([]string, error) {
+	entries, err := os.ReadDir(filepath.Join(config.DataDir, "db_"+database))
+	if err != nil {
+		return nil, errors.New("database teu kapanggih")
+	}
+
+	var tables []string
+	for _, e := range entries {
+		if name, ok := strings.CutSuffix(e.Name(), ".schema"); ok && !e.IsDir() {
+			tables = append(tables, name)
+		}
+	}
+	slices.Sort(tables)
+	return tables, nil
 }
 
 func Load(database, table string) (*Definition, error) {
-	path := filepath.Join(config.DataDir, "db_"+database, table+".schema")
+	path := schemaPath(database, table)
 
 	content, err := os.ReadFile(path)
 	if err != nil {
@@ -75,7 +152,7 @@ func Load(database, table string) (*Definition, error) {
 		parts := strings.SplitN(rc, ":", 2)
 		if len(parts) == 2 {
 			colName := parts[0]
-			fullType := parts[1] 
+			fullType, def, _ := strings.Cut(parts[1], "=")
 			
 			baseType, args := parseTypeAndArgs(fullType)
 			
@@ -83,6 +160,7 @@ func Load(database, table string) (*Definition, error) {
 				Name: colName, 
 				Type: baseType,
 				Args: args,
+				Default: def,
 			})
 		}
 	}
@@ -103,57 +181,87 @@ func Load(database, table string) (*Definition, error) {
 	return def, nil
 }
 
-func (d *Definition) ValidateRow(data string) error {
-	values := strings.Split(data, "|")
+// ValidateRow mariksa sakabéh nilai hiji baris. Nilai nu null[i]-na true
+// (kosong/null) teu dipariksa; null == nil hartina euweuh null.
+func (d *Definition) ValidateRow(values []string, null []bool) error {
 	if len(values) != len(d.Columns) {
 		return errors.New("jumlah kolom teu sesuai")
 	}
 
 	for i, col := range d.Columns {
-		val := strings.TrimSpace(values[i])
+		if null != nil && null[i] && values[i] == "" {
+			continue
+		}
+		if err := col.Validate(values[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		switch col.Type {
-		case "INT":
-			if _, err := strconv.Atoi(val); err != nil {
-				return fmt.Errorf("kolom '%s' kudu INT (angka)", col.Name)
-			}
-		case "FLOAT":
-			if _, err := strconv.ParseFloat(val, 64); err != nil {
-				return fmt.Errorf("kolom '%s' kudu FLOAT (desimal)", col.Name)
-			}
-		case "BOOL":
-			if val != "true" && val != "false" {
-				return fmt.Errorf("kolom '%s' kudu BOOL (true/false)", col.Name)
-			}
-		case "DATE":
-			if _, err := time.Parse("2006-01-02", val); err != nil {
-				return fmt.Errorf("kolom '%s' kudu DATE (YYYY-MM-DD)", col.Name)
-			}
-		case "CHAR":
-			limit, _ := strconv.Atoi(col.Args[0])
-			if len(val) > limit {
-				return fmt.Errorf("kolom '%s' maksimal %d karakter", col.Name, limit)
-			}
-		case "ENUM":
-			valid := false
-			for _, opt := range col.Args {
-				if val == opt {
-					valid = true
-					break
-				}
-			}
-			if !valid {
-				return fmt.Errorf("kolom '%s' kudu salah sahiji tina: %v", col.Name, col.Args)
-			}
-		case "STRING", "TEXT":
+// Validate mariksa hiji nilai numutkeun tipe kolom. Nilai kosong ngan sah
+// pikeun STRING, TEXT jeung CHAR; null ditangtukeun ku ValidateRow.
+func (col Column) Validate(value string) error {
+	val := strings.TrimSpace(value)
 
-		default:
-			return fmt.Errorf("tipe data teu dikenal: %s", col.Type)
+	switch col.Type {
+	case "INT":
+		if _, err := strconv.Atoi(val); err != nil {
+			return fmt.Errorf("kolom '%s' kudu INT (angka)", col.Name)
+		}
+	case "FLOAT":
+		if _, err := strconv.ParseFloat(val, 64); err != nil {
+			return fmt.Errorf("kolom '%s' kudu FLOAT (desimal)", col.Name)
 		}
+	case "BOOL":
+		if val != "true" && val != "false" {
+			return fmt.Errorf("kolom '%s' kudu BOOL (true/false)", col.Name)
+		}
+	case "DATE":
+		if _, err := time.Parse("2006-01-02", val); err != nil {
+			return fmt.Errorf("kolom '%s' kudu DATE (YYYY-MM-DD)", col.Name)
+		}
+	case "CHAR":
+		limit, _ := strconv.Atoi(col.Args[0])
+		if len(val) > limit {
+			return fmt.Errorf("kolom '%s' maksimal %d karakter", col.Name, limit)
+		}
+	case "ENUM":
+		valid := false
+		for _, opt := range col.Args {
+			if val == opt {
+				valid = true
+				break
+			}
+		}
+		if !valid {
+			return fmt.Errorf("kolom '%s' kudu salah sahiji tina: %v", col.Name, col.Args)
+		}
+	case "STRING", "TEXT":
+
+	default:
+		return fmt.Errorf("tipe data teu dikenal: %s", col.Type)
 	}
 	return nil
 }
 
+// TypeString mulangkeun tipe lengkep kolom saperti ditulis dina schema,
+// contona ENUM(L,P) atawa CHAR(5).
+func (col Column) TypeString() string {
+	if len(col.Args) == 0 {
+		return col.Type
+	}
+	return col.Type + "(" + strings.Join(col.Args, ",") + ")"
+}
+
+// unquote miceun tanda petik dina default ('aktif' -> aktif).
+func unquote(v string) string {
+	if len(v) >= 2 && (v[0] == '\'' || v[0] == '"') && v[len(v)-1] == v[0] {
+		return v[1 : len(v)-1]
+	}
+	return v
+}
+
 func parseBaseType(fullType string) string {
 	idx := strings.Index(fullType, "(")
 	if idx == -1 {
@@ -201,4 +309,71 @@ func (d *Definition) GetFieldNames() []string {
 		names = append(names, c.Name)
 	}
 	return names
-}
\ No newline at end of file
+}
+// Compare ngabandingkeun dua nilai kolom numutkeun tipena: -1, 0, atawa 1.
+// INT & FLOAT salaku angka, DATE numutkeun tanggal, BOOL false saméméh
+// true, jeung ENUM numutkeun urutan pilihanana dina definisi
+// (ENUM(RENDAH,SEDENG,LUHUR)). Nilai nu teu sah (contona kosong) diurutkeun
+// pangtukangna; tipe séjén dibandingkeun salaku téks. Urutan ieu dipaké
+// ku RUNTUYKEUN jeung ku indeks, jadi hasilna sarua boh aya indeks boh
+// henteu.
+func (col Column) Compare(a, b string) int {
+	switch col.Type {
+	case "INT":
+		na, errA := strconv.Atoi(a)
+		nb, errB := strconv.Atoi(b)
+		if errA == nil && errB == nil {
+			return cmp.Compare(na, nb)
+		}
+		if r, ok := compareParsed(errA == nil, errB == nil); ok {
+			return r
+		}
+	case "FLOAT":
+		fa, errA := strconv.ParseFloat(a, 64)
+		fb, errB := strconv.ParseFloat(b, 64)
+		okA, okB := errA == nil && fa == fa, errB == nil && fb == fb
+		if okA && okB {
+			return cmp.Compare(fa, fb)
+		}
+		if r, ok := compareParsed(okA, okB); ok {
+			return r
+		}
+	case "ENUM":
+		ia, ib := slices.Index(col.Args, a), slices.Index(col.Args, b)
+		if ia >= 0 && ib >= 0 {
+			return cmp.Compare(ia, ib)
+		}
+		if r, ok := compareParsed(ia >= 0, ib >= 0); ok {
+			return r
+		}
+	case "DATE":
+		ta, errA := time.Parse("2006-01-02", a)
+		tb, errB := time.Parse("2006-01-02", b)
+		if errA == nil && errB == nil {
+			return ta.Compare(tb)
+		}
+		if r, ok := compareParsed(errA == nil, errB == nil); ok {
+			return r
+		}
+	case "BOOL":
+		okA, okB := a == "true" || a == "false", b == "true" || b == "false"
+		if okA && okB {
+			return strings.Compare(a, b)
+		}
+		if r, ok := compareParsed(okA, okB); ok {
+			return r
+		}
+	}
+	return strings.Compare(a, b)
+}
+
+// compareParsed: nilai nu sah (angka, tanggal, ...) saméméh nu teu sah.
+func compareParsed(okA, okB bool) (int, bool) {
+	switch {
+	case okA && !okB:
+		return -1, true
+	case !okA && okB:
+		return 1, true
+	}
+	return 0, false
+}
@@ -0,0 +1,119 @@
+package storage
+
+import (
+	"errors"
+	"strings"
+)
+
+// =======================
+// ROW CODEC
+// =======================
+//
+// Format baris v2: kolom dipisah ku '|'. Kolom nu ngandung '|', '"', '\',
+// atawa ganti baris ditulis dina tanda petik ganda kalawan escape:
+//
+//	1|"Asep | Sunandar"|"baris hiji\nbaris dua"
+//
+// File v2 dimimitian ku FileHeader. File heubeul (tanpa header) dibaca ku
+// cara lami (strings.Split) sarta di-upgrade basa ditulis deui.
+
+// FileHeader nyaeta baris kahiji file tabel format v2.
+const FileHeader = "#maungdb:rows:v2"
+
+// EncodeRow ngarobah hiji baris jadi hiji garis téks.
+func EncodeRow(values []string) string {
+	// baris kosong hiji kolom ditulis "" supaya teu kaanggap garis kosong
+	if len(values) == 1 && values[0] == "" {
+		return `""`
+	}
+
+	var b strings.Builder
+	for i, v := range values {
+		if i > 0 {
+			b.WriteByte('|')
+		}
+		encodeField(&b, v)
+	}
+	return b.String()
+}
+
+func encodeField(b *strings.Builder, v string) {
+	if !strings.ContainsAny(v, "|\"\\\n\r") {
+		b.WriteString(v)
+		return
+	}
+
+	b.WriteByte('"')
+	for _, r := range v {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		default:
+			b.WriteRune(r)
+		}
+	}
+	b.WriteByte('"')
+}
+
+// DecodeRow ngabalikkeun garis v2 jadi baris. Ogé dipaké pikeun data
+// SIMPEN ti user, jadi "a|b" jeung a\|b duanana tiasa dianggo.
+func DecodeRow(line string) ([]string, error) {
+	var values []string
+	var cur strings.Builder
+	inQuote := false
+	quoted := false
+
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+
+		switch {
+		case c == '\\':
+			if i+1 >= len(line) {
+				return nil, errors.New("escape '\\' teu lengkep")
+			}
+			i++
+			switch line[i] {
+			case 'n':
+				cur.WriteByte('\n')
+			case 'r':
+				cur.WriteByte('\r')
+			case 't':
+				cur.WriteByte('\t')
+			default:
+				cur.WriteByte(line[i])
+			}
+
+		case c == '"' && inQuote:
+			inQuote = false
+
+		case c == '"' && !quoted && cur.Len() == 0:
+			inQuote = true
+			quoted = true
+
+		case c == '|' && !inQuote:
+			values = append(values, cur.String())
+			cur.Reset()
+			quoted = false
+
+		default:
+			cur.WriteByte(c)
+		}
+	}
+
+	if inQuote {
+		return nil, errors.New("tanda petik teu ditutup")
+	}
+
+	return append(values, cur.String()), nil
+}
+
+// decodeLegacy maca baris format heubeul (saméméh v2).
+func decodeLegacy(line string) []string {
+	return strings.Split(line, "|")
+}
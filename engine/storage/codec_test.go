@@ -0,0 +1,44 @@
+package storage
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestRowCodecRoundTrip(t *testing.T) {
+	rows := [][]string{
+		{"1", "Asep", "30"},
+		{"2", "Siti | Aminah", `kutip "ganda"`},
+		{"3", "baris hiji\nbaris dua", `C:\data`, "\r"},
+		{"4", "", ""},
+		{""},
+		{"Jum'at", "Kopi & Teh"},
+	}
+	for _, row := range rows {
+		line := EncodeRow(row)
+		got, err := DecodeRow(line)
+		if err != nil {
+			t.Errorf("%q: %v", line, err)
+			continue
+		}
+		if !slices.Equal(got, row) {
+			t.Errorf("%q: meunang %q, kuduna %q", line, got, row)
+		}
+	}
+}
+
+func TestDecodeRowUserInput(t *testing.T) {
+	got, err := DecodeRow(`1|Asep\|Sunandar|"a|b"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{"1", "Asep|Sunandar", "a|b"}; !slices.Equal(got, want) {
+		t.Errorf("meunang %q, kuduna %q", got, want)
+	}
+
+	for _, line := range []string{`1|abc\`, `1|"abc`} {
+		if _, err := DecodeRow(line); err == nil {
+			t.Errorf("%q: kuduna error", line)
+		}
+	}
+}
@@ -2,10 +2,13 @@ package storage
 
 import (
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 
+	"github.com/febrd/maungdb/engine/schema"
 	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
 )
 
 func CreateDatabase(name string) error {
@@ -22,3 +25,50 @@ func CreateDatabase(name string) error {
 func DatabasePath(name string) string {
 	return filepath.Join(config.DataDir, "db_"+name)
 }
+
+// DropDatabase miceun diréktori database sakabéhna (schema, data, indeks).
+func DropDatabase(name string) error {
+	dbPath := DatabasePath(name)
+	if _, err := os.Stat(dbPath); err != nil {
+		return errors.New("database teu kapanggih")
+	}
+	if err := os.RemoveAll(dbPath); err != nil {
+		return err
+	}
+	return fsutil.SyncDir(config.DataDir)
+}
+
+// DatabaseExists: diréktori database geus aya.
+func DatabaseExists(name string) bool {
+	info, err := os.Stat(DatabasePath(name))
+	return err == nil && info.IsDir()
+}
+
+// renameDatabaseDir ngaganti ngaran diréktori database dina hiji rename, jadi
+// schema, data, indeks jeung konci pindah babarengan. Sakabéh tabel dikonci
+// exclusive (urutan ngaran) salila rename.
+func renameDatabaseDir(database, newDatabase string) error {
+	if !DatabaseExists(database) {
+		return errors.New("database teu kapanggih")
+	}
+	if _, err := os.Stat(DatabasePath(newDatabase)); err == nil {
+		return fmt.Errorf("database '%s' geus aya", newDatabase)
+	}
+
+	tables, err := schema.Tables(database)
+	if err != nil {
+		return err
+	}
+	for _, table := range tables {
+		unlock, err := lockTable(database, table, true)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	if err := os.Rename(DatabasePath(database), DatabasePath(newDatabase)); err != nil {
+		return err
+	}
+	return fsutil.SyncDir(config.DataDir)
+}
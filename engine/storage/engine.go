@@ -0,0 +1,90 @@
+package storage
+
+// =======================
+// STORAGE ENGINE
+// =======================
+//
+// Executor teu langsung nyabak file; manéhna ngaliwatan Engine. Aya dua
+// engine: FileEngine (file .mg di maung_data) jeung MemoryEngine (RAM wungkul,
+// pikeun demo / kelas / tés).
+
+// Engine nyaeta backend panyimpenan baris tabel.
+type Engine interface {
+	// Open muka tabel dina database. Tabel nu can boga baris tetep bisa dibuka.
+	Open(database, table string) (Table, error)
+
+	// Drop miceun sakabéh baris & indeks tabel (schema diurus ku paket schema).
+	Drop(database, table string) error
+
+	// Rename mindahkeun baris & indeks tabel ka ngaran (jeung database)
+	// anyar. Tabel tujuan teu kaci geus boga baris.
+	Rename(database, table, newDatabase, newTable string) error
+
+	// RenameDatabase ngaganti ngaran database sakabéhna (schema, baris &
+	// indeks) dina hiji léngkah.
+	RenameDatabase(database, newDatabase string) error
+
+	// Commit nerapkeun parobahan transaksi ka sababaraha tabel sakaligus:
+	// boh kabéh, boh euweuh. Lamun versi hiji tabel geus robah, mulangkeun
+	// ErrConflict.
+	Commit(writes []TableWrites) error
+}
+
+// Table nyaeta tabel nu geus dibuka ku Engine.
+type Table interface {
+	// Scan muka iterator baris. Nu manggil kudu Close, sanajan eureun di
+	// tengah jalan (contona SAKADAR geus cumpon).
+	Scan() (RowIterator, error)
+
+	Insert(rows ...[]string) error
+
+	// Update ngaliwatkeun unggal baris ka fn. Lamun fn mulangkeun true,
+	// baris diganti ku hasilna. Lamun fn mulangkeun error, euweuh nu robah.
+	Update(fn UpdateFunc) (int, error)
+
+	// Delete miceun baris nu fn-na mulangkeun true.
+	Delete(fn MatchFunc) (int, error)
+
+	// Upsert ngaliwatkeun unggal baris ka fn (saperti Update), tuluy
+	// nambahkeun baris ti rest, nu disebut sanggeus sakabéh baris
+	// diliwatan. Kabéh dina hiji konci exclusive, jadi teu aya tulisan séjén
+	// di antarana. Lamun fn atawa rest mulangkeun error, euweuh nu robah.
+	Upsert(fn UpdateFunc, rest RestFunc) (int, error)
+
+	// CreateIndex ngawangun indeks anyar dina kolom info.Pos. Indeks
+	// diropéa otomatis ku Insert, Update jeung Delete.
+	CreateIndex(info IndexInfo) error
+
+	// Indexes mulangkeun daptar indeks tabel.
+	Indexes() ([]IndexInfo, error)
+
+	// IndexScan muka iterator baris nu nilai kolomna aya dina rentang r,
+	// diurutkeun numutkeun kolom éta.
+	IndexScan(name string, r KeyRange) (RowIterator, error)
+
+	// Version robah unggal aya tulisan kana tabel (dipaké mariksa bentrok
+	// transaksi).
+	Version() (string, error)
+}
+
+// RowIterator ngaliwatan baris hiji-hiji tanpa ngamuat sakabéh tabel.
+//
+//	it, _ := t.Scan()
+//	defer it.Close()
+//	for it.Next() {
+//		row := it.Row()
+//	}
+//	err := it.Err()
+type RowIterator interface {
+	Next() bool
+	Row() []string
+	Err() error
+	Close() error
+}
+
+type UpdateFunc func(row []string) ([]string, bool, error)
+
+type MatchFunc func(row []string) (bool, error)
+
+// RestFunc mulangkeun baris nu ditambahkeun ku Upsert.
+type RestFunc func() ([][]string, error)
@@ -0,0 +1,260 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
+)
+
+// FileEngine nyimpen baris dina file .mg / .maung di handapeun config.DataDir.
+type FileEngine struct{}
+
+func NewFileEngine() *FileEngine {
+	return &FileEngine{}
+}
+
+func (e *FileEngine) Open(database, table string) (Table, error) {
+	if database == "" {
+		return nil, errors.New("can use database heula")
+	}
+	if _, err := tablePath(database, table); err != nil {
+		return nil, err
+	}
+	return &fileTable{database: database, table: table}, nil
+}
+
+func (e *FileEngine) Drop(database, table string) error {
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+
+	unlock, err := lockTable(database, table, true)
+	if err != nil {
+		return err
+	}
+	err = dropFiles(database, table)
+	unlock()
+	if err != nil {
+		return err
+	}
+
+	// file konci ogé dipiceun supaya database teu pinuh ku sésa tabel
+	return removeIfExists(lockFilePath(database, table))
+}
+
+func dropFiles(database, table string) error {
+	for _, ext := range config.AllowedExt {
+		p := filepath.Join(DatabasePath(database), table+ext)
+		if err := removeIfExists(p); err != nil {
+			return err
+		}
+	}
+
+	indexes, err := indexFiles(database, table)
+	if err != nil {
+		return err
+	}
+	for _, p := range indexes {
+		if err := removeIfExists(p); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Rename mindahkeun file tabel jeung file indeksna. Dua tabel dikonci
+// exclusive dina urutan ngaran (sarua jeung commit).
+func (e *FileEngine) Rename(database, table, newDatabase, newTable string) error {
+	if database == newDatabase && table == newTable {
+		return nil
+	}
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+	if _, err := os.Stat(DatabasePath(newDatabase)); err != nil {
+		return errors.New("database teu kapanggih")
+	}
+
+	locks := sortWrites([]TableWrites{
+		{Database: database, Table: table},
+		{Database: newDatabase, Table: newTable},
+	})
+	var unlocks []func()
+	release := func() {
+		for i := len(unlocks) - 1; i >= 0; i-- {
+			unlocks[i]()
+		}
+	}
+	for _, w := range locks {
+		unlock, err := lockTable(w.Database, w.Table, true)
+		if err != nil {
+			release()
+			return err
+		}
+		unlocks = append(unlocks, unlock)
+	}
+	err := renameFiles(database, table, newDatabase, newTable)
+	release()
+	if err != nil {
+		return err
+	}
+	return removeIfExists(lockFilePath(database, table))
+}
+
+func renameFiles(database, table, newDatabase, newTable string) error {
+	for _, ext := range config.AllowedExt {
+		p := filepath.Join(DatabasePath(newDatabase), newTable+ext)
+		if _, err := os.Stat(p); err == nil {
+			return fmt.Errorf("tabel '%s' geus boga data", newTable)
+		}
+	}
+
+	src, err := tablePath(database, table)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(src); err == nil {
+		dst := filepath.Join(DatabasePath(newDatabase), newTable+filepath.Ext(src))
+		if err := os.Rename(src, dst); err != nil {
+			return err
+		}
+	}
+
+	indexes, err := indexFiles(database, table)
+	if err != nil {
+		return err
+	}
+	for _, p := range indexes {
+		name := strings.TrimPrefix(filepath.Base(p), table+".")
+		if err := os.Rename(p, filepath.Join(DatabasePath(newDatabase), newTable+"."+name)); err != nil {
+			return err
+		}
+	}
+
+	if err := fsutil.SyncDir(DatabasePath(newDatabase)); err != nil {
+		return err
+	}
+	if newDatabase != database {
+		return fsutil.SyncDir(DatabasePath(database))
+	}
+	return nil
+}
+
+func (e *FileEngine) RenameDatabase(database, newDatabase string) error {
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+	return renameDatabaseDir(database, newDatabase)
+}
+
+func removeIfExists(path string) error {
+	if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+func (e *FileEngine) Commit(writes []TableWrites) error {
+	return commitWrites(writes)
+}
+
+type fileTable struct {
+	database string
+	table    string
+}
+
+func (t *fileTable) Scan() (RowIterator, error) {
+	return scanRows(t.database, t.table)
+}
+
+func (t *fileTable) Insert(rows ...[]string) error {
+	return appendRows(t.database, t.table, rows...)
+}
+
+func (t *fileTable) Update(fn UpdateFunc) (int, error) {
+	count := 0
+
+	err := rewriteRows(t.database, t.table, func(row []string) ([]string, bool, error) {
+		newRow, ok, err := fn(row)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			count++
+			return newRow, true, nil
+		}
+		return row, true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *fileTable) Upsert(fn UpdateFunc, rest RestFunc) (int, error) {
+	count := 0
+
+	err := upsertRows(t.database, t.table, func(row []string) ([]string, bool, error) {
+		newRow, ok, err := fn(row)
+		if err != nil {
+			return nil, false, err
+		}
+		if ok {
+			count++
+			return newRow, true, nil
+		}
+		return row, true, nil
+	}, rest)
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *fileTable) Delete(fn MatchFunc) (int, error) {
+	count := 0
+
+	err := rewriteRows(t.database, t.table, func(row []string) ([]string, bool, error) {
+		hit, err := fn(row)
+		if err != nil {
+			return nil, false, err
+		}
+		if hit {
+			count++
+			return nil, false, nil
+		}
+		return row, true, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+func (t *fileTable) CreateIndex(info IndexInfo) error {
+	return createIndex(t.database, t.table, info)
+}
+
+func (t *fileTable) Indexes() ([]IndexInfo, error) {
+	return listIndexes(t.database, t.table)
+}
+
+func (t *fileTable) IndexScan(name string, r KeyRange) (RowIterator, error) {
+	return indexScan(t.database, t.table, name, r)
+}
+
+func (t *fileTable) Version() (string, error) {
+	if err := ensureRecovered(); err != nil {
+		return "", err
+	}
+	path, err := tablePath(t.database, t.table)
+	if err != nil {
+		return "", err
+	}
+	return fileVersion(path), nil
+}
@@ -0,0 +1,13 @@
+//go:build !unix
+
+package storage
+
+import "os"
+
+// Di luar unix teu aya flock; konci dina prosés (RWMutex) wungkul nu dipake.
+
+func flock(f *os.File, exclusive bool) error { return nil }
+
+func funlock(f *os.File) error { return nil }
+
+func tryFlock(f *os.File, exclusive bool) (bool, error) { return true, nil }
@@ -0,0 +1,766 @@
+package storage
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/febrd/maungdb/engine/index"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/internal/fsutil"
+)
+
+// =======================
+// SECONDARY INDEX
+// =======================
+//
+// Indeks disimpen di gigireun file tabel: <tabel>.<ngaran>.idx. Eusina
+// B-tree (paket index) nu titik-titikna ditulis postorder, hiji titik hiji
+// garis, jadi titik indung nyatet offset anak-anakna. Query ngan maca titik
+// nu diliwatan (akar -> daun), teu kudu ngamuat sakabéh indeks.
+//
+//	#maungdb:index:v2
+//	<ngaran>|<kolom>|<posisi>|<tipe>|<args>|<degree>
+//	R|<offset akar>|<tungtung snapshot>|<jumlah item>
+//	N|<jumlah item>|<jumlah anak>|<nilai>|<id>|...|<offset anak>|...
+//	S|<ukuran tabel>|<waktu robah>
+//	+|<nilai>|<id>                    (tambahan ti SIMPEN)
+//	S|...
+//
+// ID baris = offset byte baris dina file tabel. SIMPEN ngan nambahkeun
+// garis "+" di tungtung; lamun geus loba, indeks ditulis ulang (compaction).
+// Cap S nyatet kaayaan file tabel nu cocog jeung indeks. Lamun cap
+// panungtung teu sarua jeung file tabel ayeuna (contona crash saméméh
+// indeks kaburu ditulis), indeks diwangun deui tina tabel.
+//
+// Nilai diurutkeun ku schema.Column.Compare. Indeks v1 diurutkeun ku urutan
+// heubeul (ENUM, DATE & BOOL salaku téks), jadi dianggap basi sarta
+// diwangun deui.
+
+const (
+	indexHeader   = "#maungdb:index:v2"
+	indexHeaderV1 = "#maungdb:index:v1"
+	indexExt      = ".idx"
+
+	// compaction lamun tambahan leuwih ti ieu sarta leuwih ti 1/32 snapshot
+	maxIndexDeltas = 1024
+)
+
+// IndexInfo ngajelaskeun hiji indeks tabel.
+type IndexInfo struct {
+	Name   string
+	Column schema.Column
+	Pos    int // posisi kolom dina baris
+}
+
+// KeyRange nyaeta rentang nilai pikeun IndexScan. Low / High nil hartina
+// teu diwates. Desc ngabalikkeun urutan hasil; baris nu nilaina sarua tetep
+// numutkeun posisina dina tabel. RowOrder: hasilna numutkeun posisi baris
+// dina tabel (saperti Scan), lain numutkeun nilai.
+type KeyRange struct {
+	Low      *index.Bound
+	High     *index.Bound
+	Desc     bool
+	RowOrder bool
+}
+
+// tableIndex nyaeta indeks nu keur dibuka: snapshot (tangkal disk atawa
+// memori) + overlay tambahan ti SIMPEN nu can di-compact.
+type tableIndex struct {
+	path    string
+	info    IndexInfo
+	file    *os.File
+	tree    *index.BTree
+	overlay *index.BTree
+	deltas  int
+	size    int64
+	mod     int64
+	stale   bool
+}
+
+func (ti *tableIndex) Close() {
+	if ti.file != nil {
+		ti.file.Close()
+		ti.file = nil
+	}
+}
+
+// iterate mulangkeun item dina rentang r tina snapshot jeung overlay.
+func (ti *tableIndex) iterate(r KeyRange) index.Iterator {
+	it := cursorFor(ti.tree, r)
+	if ti.overlay.Len() == 0 {
+		return ordered(ti.tree, it, r)
+	}
+	merged := ti.tree.Merge(it, cursorFor(ti.overlay, r), r.Desc && !r.RowOrder)
+	return ordered(ti.tree, merged, r)
+}
+
+func cursorFor(tree *index.BTree, r KeyRange) index.Iterator {
+	if r.Desc && !r.RowOrder {
+		return tree.Descend(r.Low, r.High)
+	}
+	return tree.Ascend(r.Low, r.High)
+}
+
+// ordered nerapkeun urutan r kana item ti cursorFor.
+func ordered(tree *index.BTree, it index.Iterator, r KeyRange) index.Iterator {
+	switch {
+	case r.RowOrder:
+		return index.ByID(it)
+	case r.Desc:
+		return tree.TiesByID(it)
+	}
+	return it
+}
+
+func closeIndexes(tis []*tableIndex) {
+	for _, ti := range tis {
+		ti.Close()
+	}
+}
+
+func indexPath(database, table, name string) string {
+	return filepath.Join(DatabasePath(database), table+"."+name+indexExt)
+}
+
+func validIndexName(name string) bool {
+	return name != "" && !strings.ContainsAny(name, `./\|`)
+}
+
+// indexFiles mulangkeun path sakabéh file indeks hiji tabel.
+func indexFiles(database, table string) ([]string, error) {
+	dir := DatabasePath(database)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var paths []string
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, table+".") || !strings.HasSuffix(name, indexExt) {
+			continue
+		}
+		if validIndexName(strings.TrimSuffix(strings.TrimPrefix(name, table+"."), indexExt)) {
+			paths = append(paths, filepath.Join(dir, name))
+		}
+	}
+	sort.Strings(paths)
+	return paths, nil
+}
+
+// listIndexes ngan maca katerangan unggal file indeks.
+func listIndexes(database, table string) ([]IndexInfo, error) {
+	paths, err := indexFiles(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	var out []IndexInfo
+	for _, p := range paths {
+		f, err := os.Open(p)
+		if err != nil {
+			return nil, err
+		}
+		info, _, err := readIndexHead(bufio.NewReader(f))
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("%s: %v", filepath.Base(p), err)
+		}
+		out = append(out, info)
+	}
+	return out, nil
+}
+
+// openIndexes muka sakabéh indeks tabel nu cocog jeung file tabel ayeuna;
+// nu basi diwangun deui sarta disimpen. Nu manggil kudu nyekel konci tabel
+// exclusive sarta nutup hasilna ku closeIndexes.
+func openIndexes(database, table, tablePath string) ([]*tableIndex, error) {
+	out, stale, err := loadIndexes(database, table, tablePath)
+	if err != nil || len(stale) == 0 {
+		return out, err
+	}
+
+	if err := buildIndexes(tablePath, stale); err != nil {
+		closeIndexes(out)
+		return nil, err
+	}
+	for _, ti := range stale {
+		if err := writeIndex(ti); err != nil {
+			closeIndexes(out)
+			return nil, err
+		}
+	}
+	return out, nil
+}
+
+// loadIndexes muka sakabéh indeks tabel tanpa ngawangun deui nu basi
+// (hasil kadua). Cukup ku konci shared.
+func loadIndexes(database, table, tablePath string) ([]*tableIndex, []*tableIndex, error) {
+	paths, err := indexFiles(database, table)
+	if err != nil || len(paths) == 0 {
+		return nil, nil, err
+	}
+
+	size, mod := fileStamp(tablePath)
+
+	var out, stale []*tableIndex
+	for _, p := range paths {
+		ti, err := openIndex(p, size, mod)
+		if err != nil {
+			closeIndexes(out)
+			return nil, nil, err
+		}
+		if ti.stale {
+			stale = append(stale, ti)
+		}
+		out = append(out, ti)
+	}
+	return out, stale, nil
+}
+
+// refreshIndexes ngawangun deui sarta nyimpen indeks nu basi dina konci
+// exclusive (dua nu maca teu meunang nulis file indeks nu sarua babarengan).
+func refreshIndexes(database, table, tablePath string) error {
+	unlock, err := lockTable(database, table, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	tis, err := openIndexes(database, table, tablePath)
+	closeIndexes(tis)
+	return err
+}
+
+// openIndex muka file indeks. Lamun ruksak, v1, atawa capna teu sarua jeung
+// (size, mod), hasilna ditandaan stale.
+func openIndex(path string, size, mod int64) (*tableIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	info, head, err := readIndexHead(bufio.NewReader(f))
+	if err != nil {
+		f.Close()
+		return nil, fmt.Errorf("%s: %v", filepath.Base(path), err)
+	}
+
+	ti := &tableIndex{path: path, info: info, file: f}
+	if head.v1 {
+		ti.Close()
+		ti.stale = true
+	} else if err := ti.load(head); err != nil || ti.size != size || ti.mod != mod {
+		ti.Close()
+		ti.stale = true
+	}
+	return ti, nil
+}
+
+// indexHead nyaeta eusi garis R.
+type indexHead struct {
+	degree int
+	root   int64
+	end    int64
+	length int
+	v1     bool // urutan heubeul, kudu diwangun deui
+}
+
+func readIndexHead(br *bufio.Reader) (IndexInfo, indexHead, error) {
+	var head indexHead
+
+	first, _ := br.ReadString('\n')
+	switch strings.TrimRight(first, "\n") {
+	case indexHeader:
+	case indexHeaderV1:
+		head.v1 = true
+	default:
+		return IndexInfo{}, head, errors.New("lain file indeks")
+	}
+
+	meta, err := readIndexLine(br)
+	if err != nil || len(meta) != 6 {
+		return IndexInfo{}, head, errors.New("katerangan indeks ruksak")
+	}
+	pos, err1 := strconv.Atoi(meta[2])
+	degree, err2 := strconv.Atoi(meta[5])
+	if err1 != nil || err2 != nil {
+		return IndexInfo{}, head, errors.New("katerangan indeks ruksak")
+	}
+
+	col := schema.Column{Name: meta[1], Type: meta[3]}
+	if meta[4] != "" {
+		col.Args = strings.Split(meta[4], ",")
+	}
+	info := IndexInfo{Name: meta[0], Column: col, Pos: pos}
+
+	r, err := readIndexLine(br)
+	if err != nil || len(r) != 4 || r[0] != "R" {
+		return info, head, errors.New("katerangan indeks ruksak")
+	}
+	root, err1 := strconv.ParseInt(r[1], 10, 64)
+	end, err2 := strconv.ParseInt(r[2], 10, 64)
+	length, err3 := strconv.Atoi(r[3])
+	if err1 != nil || err2 != nil || err3 != nil {
+		return info, head, errors.New("katerangan indeks ruksak")
+	}
+
+	return info, indexHead{degree: degree, root: root, end: end, length: length, v1: head.v1}, nil
+}
+
+func readIndexLine(br *bufio.Reader) ([]string, error) {
+	line, err := br.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return nil, err
+	}
+	if !strings.HasSuffix(line, "\n") {
+		// garis panungtung nu teu anggeus ditulis
+		return nil, io.ErrUnexpectedEOF
+	}
+	return DecodeRow(strings.TrimSuffix(line, "\n"))
+}
+
+// load muka tangkal disk sarta maca tambahan di tungtung file. Tambahan ngan
+// diterapkeun nepi ka cap S panungtung.
+func (ti *tableIndex) load(head indexHead) error {
+	br := bufio.NewReader(nil)
+	loadNode := func(off int64) (*index.Node, error) {
+		br.Reset(io.NewSectionReader(ti.file, off, maxRowSize))
+		row, err := readIndexLine(br)
+		if err != nil {
+			return nil, fmt.Errorf("titik indeks %d: %v", off, err)
+		}
+		return decodeNode(row)
+	}
+
+	root, err := loadNode(head.root)
+	if err != nil {
+		return err
+	}
+	ti.tree = index.Open(head.degree, ti.info.Column.Compare, root, head.length, loadNode)
+	ti.overlay = newIndexTree(ti.info)
+
+	tail := bufio.NewReader(io.NewSectionReader(ti.file, head.end, 1<<62))
+	var pending []index.Item
+	stamped := false
+
+	for {
+		row, err := readIndexLine(tail)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+
+		switch {
+		case len(row) == 3 && row[0] == "+":
+			id, err := strconv.ParseInt(row[2], 10, 64)
+			if err != nil {
+				return errors.New("catetan indeks ruksak")
+			}
+			pending = append(pending, index.Item{Key: row[1], ID: id})
+		case len(row) == 3 && row[0] == "S":
+			size, err1 := strconv.ParseInt(row[1], 10, 64)
+			mod, err2 := strconv.ParseInt(row[2], 10, 64)
+			if err1 != nil || err2 != nil {
+				return errors.New("cap indeks ruksak")
+			}
+			for _, it := range pending {
+				ti.overlay.Insert(it.Key, it.ID)
+			}
+			ti.deltas += len(pending)
+			pending = pending[:0]
+			ti.size, ti.mod, stamped = size, mod, true
+		default:
+			return errors.New("catetan indeks ruksak")
+		}
+	}
+
+	if !stamped {
+		return errors.New("cap indeks teu aya")
+	}
+	return nil
+}
+
+func decodeNode(row []string) (*index.Node, error) {
+	bad := errors.New("titik indeks ruksak")
+	if len(row) < 3 || row[0] != "N" {
+		return nil, bad
+	}
+	nItems, err1 := strconv.Atoi(row[1])
+	nChildren, err2 := strconv.Atoi(row[2])
+	if err1 != nil || err2 != nil || len(row) != 3+2*nItems+nChildren {
+		return nil, bad
+	}
+
+	n := &index.Node{Items: make([]index.Item, nItems)}
+	for i := 0; i < nItems; i++ {
+		id, err := strconv.ParseInt(row[4+2*i], 10, 64)
+		if err != nil {
+			return nil, bad
+		}
+		n.Items[i] = index.Item{Key: row[3+2*i], ID: id}
+	}
+
+	if nChildren > 0 {
+		n.Offsets = make([]int64, nChildren)
+		for i, raw := range row[3+2*nItems:] {
+			off, err := strconv.ParseInt(raw, 10, 64)
+			if err != nil {
+				return nil, bad
+			}
+			n.Offsets[i] = off
+		}
+	}
+	return n, nil
+}
+
+// buildIndexes ngawangun deui indeks (dina memori) tina sakabéh baris file tabel.
+func buildIndexes(tablePath string, tis []*tableIndex) error {
+	size, mod := fileStamp(tablePath)
+	for _, ti := range tis {
+		ti.Close()
+		ti.tree = newIndexTree(ti.info)
+		ti.overlay = newIndexTree(ti.info)
+		ti.deltas = 0
+		ti.size, ti.mod = size, mod
+		ti.stale = false
+	}
+
+	f, err := os.Open(tablePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	r := newRowReader(f, filepath.Base(tablePath))
+	for r.Next() {
+		for _, ti := range tis {
+			ti.tree.Insert(indexKey(r.Row(), ti.info.Pos), r.Offset())
+		}
+	}
+	return r.Err()
+}
+
+func newIndexTree(info IndexInfo) *index.BTree {
+	return index.New(index.DefaultDegree, info.Column.Compare)
+}
+
+func indexKey(row []string, pos int) string {
+	if pos < len(row) {
+		return row[pos]
+	}
+	return ""
+}
+
+// indexWriter nulis garis-garis indeks bari ngitung offset-na.
+type indexWriter struct {
+	w   *bufio.Writer
+	off int64
+}
+
+func (iw *indexWriter) line(fields []string) int64 {
+	off := iw.off
+	s := EncodeRow(fields) + "\n"
+	iw.w.WriteString(s)
+	iw.off += int64(len(s))
+	return off
+}
+
+// writeIndex nulis sakabéh indeks (atomic). ti.tree kudu tangkal memori.
+func writeIndex(ti *tableIndex) error {
+	f, err := fsutil.Create(ti.path, 0644)
+	if err != nil {
+		return err
+	}
+
+	iw := &indexWriter{w: bufio.NewWriter(f)}
+	iw.line([]string{indexHeader})
+	iw.line([]string{
+		ti.info.Name,
+		ti.info.Column.Name,
+		strconv.Itoa(ti.info.Pos),
+		ti.info.Column.Type,
+		strings.Join(ti.info.Column.Args, ","),
+		strconv.Itoa(ti.tree.Degree()),
+	})
+
+	// garis R lebarna tetep; diisi sanggeus titik-titik ditulis
+	headAt := iw.line(headRow(0, 0, 0))
+	root := writeNode(iw, ti.tree.Root())
+	end := iw.off
+	iw.line([]string{"S", strconv.FormatInt(ti.size, 10), strconv.FormatInt(ti.mod, 10)})
+
+	if err := iw.w.Flush(); err != nil {
+		f.Abort()
+		return err
+	}
+	head := EncodeRow(headRow(root, end, ti.tree.Len()))
+	if _, err := f.WriteAt([]byte(head), headAt); err != nil {
+		f.Abort()
+		return err
+	}
+	return f.Commit()
+}
+
+func headRow(root, end int64, length int) []string {
+	return []string{
+		"R",
+		fmt.Sprintf("%020d", root),
+		fmt.Sprintf("%020d", end),
+		fmt.Sprintf("%020d", length),
+	}
+}
+
+// writeNode nulis titik postorder; mulangkeun offset titik.
+func writeNode(iw *indexWriter, n *index.Node) int64 {
+	var offsets []string
+	for _, c := range n.Children {
+		offsets = append(offsets, strconv.FormatInt(writeNode(iw, c), 10))
+	}
+
+	row := []string{"N", strconv.Itoa(len(n.Items)), strconv.Itoa(len(n.Children))}
+	for _, it := range n.Items {
+		row = append(row, it.Key, strconv.FormatInt(it.ID, 10))
+	}
+	return iw.line(append(row, offsets...))
+}
+
+// appendIndexItems nambahkeun item ti SIMPEN kana tungtung file indeks.
+// size/mod nyaeta cap file tabel sanggeus SIMPEN.
+func appendIndexItems(ti *tableIndex, items []index.Item, size, mod int64) error {
+	for _, it := range items {
+		ti.overlay.Insert(it.Key, it.ID)
+	}
+	ti.deltas += len(items)
+	ti.size, ti.mod = size, mod
+
+	if ti.deltas > maxIndexDeltas && ti.deltas > ti.tree.Len()/32 {
+		return compactIndex(ti)
+	}
+
+	f, err := os.OpenFile(ti.path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var b strings.Builder
+	for _, it := range items {
+		b.WriteString(EncodeRow([]string{"+", it.Key, strconv.FormatInt(it.ID, 10)}) + "\n")
+	}
+	b.WriteString(EncodeRow([]string{"S", strconv.FormatInt(size, 10), strconv.FormatInt(mod, 10)}) + "\n")
+
+	_, err = f.WriteString(b.String())
+	return err
+}
+
+// compactIndex ngahijikeun snapshot jeung overlay jadi snapshot anyar.
+func compactIndex(ti *tableIndex) error {
+	tree := newIndexTree(ti.info)
+	it := ti.iterate(KeyRange{})
+	for it.Next() {
+		tree.Insert(it.Item().Key, it.Item().ID)
+	}
+	if err := it.Err(); err != nil {
+		return err
+	}
+
+	ti.Close()
+	ti.tree = tree
+	ti.overlay = newIndexTree(ti.info)
+	ti.deltas = 0
+	return writeIndex(ti)
+}
+
+// =======================
+// FILE ENGINE: INDEX OPS
+// =======================
+
+// createIndex ngawangun indeks anyar tina baris nu geus aya.
+func createIndex(database, table string, info IndexInfo) error {
+	if !validIndexName(info.Name) {
+		return errors.New("ngaran indeks teu valid")
+	}
+
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+
+	path, err := tablePath(database, table)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockTable(database, table, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	p := indexPath(database, table, info.Name)
+	if _, err := os.Stat(p); err == nil {
+		return fmt.Errorf("indeks '%s' geus aya", info.Name)
+	}
+
+	format, err := fileFormat(path)
+	if err != nil {
+		return err
+	}
+	// file heubeul di-upgrade heula supaya offset baris tetep
+	if format == formatLegacy {
+		keep := func(row []string) ([]string, bool, error) { return row, true, nil }
+		if err := rewriteLocked(database, path, keep); err != nil {
+			return err
+		}
+	}
+
+	ti := &tableIndex{path: p, info: info}
+	if err := buildIndexes(path, []*tableIndex{ti}); err != nil {
+		return err
+	}
+	return writeIndex(ti)
+}
+
+// indexScan muka iterator baris numutkeun indeks. Konci shared dicekel
+// nepi ka Close.
+func indexScan(database, table, name string, r KeyRange) (RowIterator, error) {
+	if err := ensureRecovered(); err != nil {
+		return nil, err
+	}
+
+	path, err := tablePath(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockTable(database, table, false)
+	if err != nil {
+		return nil, err
+	}
+
+	tis, stale, err := loadIndexes(database, table, path)
+	if err == nil && len(stale) > 0 {
+		// indeks basi disimpen deui dina konci exclusive, tuluy dimuat deui
+		closeIndexes(tis)
+		unlock()
+		if err := refreshIndexes(database, table, path); err != nil {
+			return nil, err
+		}
+		if unlock, err = lockTable(database, table, false); err != nil {
+			return nil, err
+		}
+		tis, stale, err = loadIndexes(database, table, path)
+	}
+	if err == nil && len(stale) > 0 {
+		// basi deui ti antara dua konci: diwangun di memori wungkul
+		err = buildIndexes(path, stale)
+	}
+	if err != nil {
+		closeIndexes(tis)
+		unlock()
+		return nil, err
+	}
+
+	var ti *tableIndex
+	for _, t := range tis {
+		if t.info.Name == name {
+			ti = t
+		} else {
+			t.Close()
+		}
+	}
+	if ti == nil {
+		unlock()
+		return nil, fmt.Errorf("indeks '%s' teu kapanggih", name)
+	}
+
+	release := func() {
+		ti.Close()
+		unlock()
+	}
+
+	format, err := fileFormat(path)
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	it := &indexIterator{items: ti.iterate(r), unlock: release, v2: format == formatV2}
+	if format == formatEmpty {
+		return it, nil
+	}
+
+	if it.file, err = os.Open(path); err != nil {
+		release()
+		return nil, errors.New("table teu kapanggih")
+	}
+	it.br = bufio.NewReader(it.file)
+	return it, nil
+}
+
+type indexIterator struct {
+	items  index.Iterator
+	file   *os.File
+	br     *bufio.Reader
+	v2     bool
+	row    []string
+	err    error
+	unlock func()
+}
+
+func (it *indexIterator) Next() bool {
+	if it.err != nil || it.file == nil {
+		return false
+	}
+	if !it.items.Next() {
+		it.err = it.items.Err()
+		return false
+	}
+
+	off := it.items.Item().ID
+	it.br.Reset(io.NewSectionReader(it.file, off, maxRowSize+2))
+	line, err := it.br.ReadString('\n')
+	if err != nil && err != io.EOF {
+		it.err = err
+		return false
+	}
+	line = strings.TrimRight(line, "\r\n")
+
+	if !it.v2 {
+		it.row = decodeLegacy(line)
+		return true
+	}
+	if it.row, err = DecodeRow(line); err != nil {
+		it.err = fmt.Errorf("%s offset %d: %v", it.file.Name(), off, err)
+		return false
+	}
+	return true
+}
+
+func (it *indexIterator) Row() []string { return it.row }
+
+func (it *indexIterator) Err() error { return it.err }
+
+func (it *indexIterator) Close() error {
+	if it.unlock == nil {
+		return nil
+	}
+	it.unlock()
+	it.unlock = nil
+	if it.file != nil {
+		return it.file.Close()
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package storage
+
+import (
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/febrd/maungdb/engine/index"
+	"github.com/febrd/maungdb/engine/schema"
+	"github.com/febrd/maungdb/internal/config"
+)
+
+// testData nyieun maung_data samentara kalawan database "a".
+func testData(t *testing.T) {
+	t.Helper()
+	saved := config.DataDir
+	config.DataDir = t.TempDir()
+	t.Cleanup(func() { config.DataDir = saved })
+
+	if err := Init(); err != nil {
+		t.Fatal(err)
+	}
+	if err := CreateDatabase("a"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// indexRows maca sakabéh baris IndexScan.
+func indexRows(t *testing.T, tbl Table, name string, r KeyRange) [][]string {
+	t.Helper()
+	it, err := tbl.IndexScan(name, r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var rows [][]string
+	for it.Next() {
+		rows = append(rows, it.Row())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+// column ngumpulkeun kolom ka-pos unggal baris.
+func column(rows [][]string, pos int) []string {
+	out := make([]string, len(rows))
+	for i, r := range rows {
+		out[i] = r[pos]
+	}
+	return out
+}
+
+var umurIndex = IndexInfo{Name: "ix_umur", Column: schema.Column{Name: "umur", Type: "INT"}, Pos: 1}
+
+func TestIndexScanRange(t *testing.T) {
+	testData(t)
+	tbl, err := NewFileEngine().Open("a", "peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Insert([]string{"1", "30"}, []string{"2", "22"}, []string{"3", "-5"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.CreateIndex(umurIndex); err != nil {
+		t.Fatal(err)
+	}
+	// baris sanggeus indeks dijieun asup kana overlay
+	if err := tbl.Insert([]string{"4", "100"}, []string{"5", "22"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := indexRows(t, tbl, "ix_umur", KeyRange{})
+	if got, want := column(rows, 1), []string{"-5", "22", "22", "30", "100"}; !slices.Equal(got, want) {
+		t.Errorf("naék: %v, kuduna %v", got, want)
+	}
+
+	r := KeyRange{Low: &index.Bound{Key: "22", Inclusive: false}, High: &index.Bound{Key: "100", Inclusive: true}}
+	rows = indexRows(t, tbl, "ix_umur", r)
+	if got, want := column(rows, 0), []string{"1", "4"}; !slices.Equal(got, want) {
+		t.Errorf("rentang: %v, kuduna %v", got, want)
+	}
+}
+
+func TestIndexScanRebuildsStaleIndex(t *testing.T) {
+	testData(t)
+	tbl, err := NewFileEngine().Open("a", "peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Insert([]string{"1", "30"}, []string{"2", "22"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.CreateIndex(umurIndex); err != nil {
+		t.Fatal(err)
+	}
+
+	// file tabel robah di luar indeks (contona ditulis ku versi heubeul)
+	path, _ := tablePath("a", "peg")
+	later := time.Now().Add(time.Hour)
+	if err := os.Chtimes(path, later, later); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := indexRows(t, tbl, "ix_umur", KeyRange{})
+	if got := column(rows, 0); !slices.Equal(got, []string{"2", "1"}) {
+		t.Errorf("hasil: %v", got)
+	}
+
+	size, mod := fileStamp(path)
+	ti, err := openIndex(indexPath("a", "peg", "ix_umur"), size, mod)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ti.Close()
+	if ti.stale {
+		t.Error("indeks nu diwangun deui teu disimpen")
+	}
+}
+
+func TestIndexV1IsRebuilt(t *testing.T) {
+	testData(t)
+	tbl, err := NewFileEngine().Open("a", "peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	prio := IndexInfo{Name: "ix_prio", Column: schema.Column{Name: "prio", Type: "ENUM", Args: []string{"RENDAH", "SEDENG", "LUHUR"}}, Pos: 1}
+	if err := tbl.Insert([]string{"1", "SEDENG"}, []string{"2", "LUHUR"}, []string{"3", ""}, []string{"4", "RENDAH"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.CreateIndex(prio); err != nil {
+		t.Fatal(err)
+	}
+
+	// indeks v1 diurutkeun salaku téks; eusina teu dipercaya
+	p := indexPath("a", "peg", "ix_prio")
+	data, err := os.ReadFile(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data = []byte(strings.Replace(string(data), indexHeader, indexHeaderV1, 1))
+	if err := os.WriteFile(p, data, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	rows := indexRows(t, tbl, "ix_prio", KeyRange{})
+	if got, want := column(rows, 0), []string{"4", "1", "2", "3"}; !slices.Equal(got, want) {
+		t.Errorf("urutan: %v, kuduna %v", got, want)
+	}
+	if data, _ := os.ReadFile(p); !strings.HasPrefix(string(data), indexHeader+"\n") {
+		t.Error("indeks v1 teu ditulis deui jadi v2")
+	}
+}
+
+func TestIndexScanDescKeepsRowOrderForTies(t *testing.T) {
+	testData(t)
+	for _, e := range []Engine{NewFileEngine(), NewMemoryEngine()} {
+		tbl, err := e.Open("a", "ties")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.Insert([]string{"1", "5"}, []string{"2", "7"}, []string{"3", "5"}, []string{"4", "7"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.CreateIndex(umurIndex); err != nil {
+			t.Fatal(err)
+		}
+		if err := tbl.Insert([]string{"5", "5"}); err != nil {
+			t.Fatal(err)
+		}
+
+		rows := indexRows(t, tbl, "ix_umur", KeyRange{Desc: true})
+		if got, want := column(rows, 0), []string{"2", "4", "1", "3", "5"}; !slices.Equal(got, want) {
+			t.Errorf("%T turun: %v, kuduna %v", e, got, want)
+		}
+		rows = indexRows(t, tbl, "ix_umur", KeyRange{Low: &index.Bound{Key: "6", Inclusive: true}, RowOrder: true})
+		if got, want := column(rows, 0), []string{"2", "4"}; !slices.Equal(got, want) {
+			t.Errorf("%T RowOrder: %v, kuduna %v", e, got, want)
+		}
+		rows = indexRows(t, tbl, "ix_umur", KeyRange{RowOrder: true, Desc: true})
+		if got, want := column(rows, 0), []string{"1", "2", "3", "4", "5"}; !slices.Equal(got, want) {
+			t.Errorf("%T RowOrder: %v, kuduna %v", e, got, want)
+		}
+		if err := e.Drop("a", "ties"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -3,12 +3,14 @@ package storage
 import (
 	"bufio"
 	"errors"
+	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
-	"github.com/febrd/maungdb/engine/auth"
+	"github.com/febrd/maungdb/engine/index"
 	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
 	"golang.org/x/crypto/bcrypt"
 )
 
@@ -28,6 +30,15 @@ func Init() error {
 		return err
 	}
 
+	// recovery: lengkepan catetan WAL nu can anggeus, tuluy piceun
+	// sésa rewrite nu teu kacatet
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+	if err := removeStaleTemps(); err != nil {
+		return err
+	}
+
 	// init default user
 	if err := initDefaultUser(systemPath); err != nil {
 		return err
@@ -62,63 +73,278 @@ func tablePath(database, table string) (string, error) {
 // APPEND DATA
 // =======================
 
-func Append(table, data string) error {
-	u, err := auth.CurrentUser()
+// appendRows nambahkeun hiji atawa leuwih baris dina hiji tulisan.
+func appendRows(database, table string, rows ...[]string) error {
+	if database == "" {
+		return errors.New("can use database heula")
+	}
+
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+
+	path, err := tablePath(database, table)
 	if err != nil {
 		return err
 	}
 
-	if u.Database == "" {
-		return errors.New("can use database heula")
+	unlock, err := lockTable(database, table, true)
+	if err != nil {
+		return err
 	}
+	defer unlock()
 
-	path, err := tablePath(u.Database, table)
+	format, err := fileFormat(path)
 	if err != nil {
 		return err
 	}
 
-	file, err := os.OpenFile(
-		path,
-		os.O_CREATE|os.O_APPEND|os.O_WRONLY,
-		0644,
-	)
+	// file heubeul: upgrade heula ka v2 (ditulis deui sakumaha ayana)
+	if format == formatLegacy {
+		keep := func(row []string) ([]string, bool, error) { return row, true, nil }
+		if err := rewriteLocked(database, path, keep); err != nil {
+			return err
+		}
+	}
+
+	p, err := prepareAppend(database, table, path, format, rows)
 	if err != nil {
 		return err
 	}
-	defer file.Close()
+	return runPending([]*pendingWrite{p})
+}
 
-	_, err = file.WriteString(data + "\n")
-	return err
+// pendingWrite nyaeta tulisan nu geus disiapkeun (data append atawa file
+// samentawis) tapi can diterapkeun ku WAL. Sababaraha pendingWrite bisa
+// diterapkeun dina hiji catetan WAL (ANGGEUSAN transaksi).
+type pendingWrite struct {
+	op     walOp
+	finish func(op walOp) // ngamutahirkeun indeks sanggeus WAL
+	abort  func()
 }
 
-func ReadAll(table string) ([]string, error) {
-	u, err := auth.CurrentUser()
+// runPending nerapkeun sakabéh tulisan dina hiji catetan WAL: boh kabéh,
+// boh euweuh.
+func runPending(ps []*pendingWrite) error {
+	ops := make([]walOp, len(ps))
+	for i, p := range ps {
+		ops[i] = p.op
+	}
+
+	if err := wal.run(ops); err != nil {
+		for _, p := range ps {
+			p.abort()
+		}
+		return err
+	}
+
+	for i, p := range ps {
+		p.finish(ops[i])
+	}
+	return nil
+}
+
+// prepareAppend nyiapkeun append baris kana file tabel (format v2 atawa kosong).
+func prepareAppend(database, table, path string, format int, rows [][]string) (*pendingWrite, error) {
+	indexes, err := openIndexes(database, table, path)
 	if err != nil {
 		return nil, err
 	}
 
-	if u.Database == "" {
+	var b strings.Builder
+	if format == formatEmpty {
+		b.WriteString(FileHeader + "\n")
+	}
+	start := b.Len()
+	lines := make([]int, len(rows))
+	for i, row := range rows {
+		line := EncodeRow(row) + "\n"
+		lines[i] = len(line)
+		b.WriteString(line)
+	}
+
+	finish := func(op walOp) {
+		defer closeIndexes(indexes)
+		if len(indexes) == 0 {
+			return
+		}
+
+		// ID baris anyar = offset append + posisi dina data
+		size, mod := fileStamp(path)
+		for _, ti := range indexes {
+			items := make([]index.Item, len(rows))
+			off := op.Offset + int64(start)
+			for i, row := range rows {
+				items[i] = index.Item{Key: indexKey(row, ti.info.Pos), ID: off}
+				off += int64(lines[i])
+			}
+			// data geus aman; indeks nu gagal ditulis bakal diwangun deui
+			_ = appendIndexItems(ti, items, size, mod)
+		}
+	}
+
+	return &pendingWrite{
+		op: walOp{
+			Kind:     walOpAppend,
+			Database: database,
+			File:     filepath.Base(path),
+			Data:     b.String(),
+		},
+		finish: finish,
+		abort:  func() { closeIndexes(indexes) },
+	}, nil
+}
+
+// =======================
+// READ DATA
+// =======================
+
+// scanRows muka iterator baris. Konci shared dicekel nepi ka Close.
+func scanRows(database, table string) (RowIterator, error) {
+	if database == "" {
 		return nil, errors.New("can use database heula")
 	}
 
-	path, err := tablePath(u.Database, table)
+	if err := ensureRecovered(); err != nil {
+		return nil, err
+	}
+
+	path, err := tablePath(database, table)
+	if err != nil {
+		return nil, err
+	}
+
+	unlock, err := lockTable(database, table, false)
 	if err != nil {
 		return nil, err
 	}
 
 	file, err := os.Open(path)
 	if err != nil {
+		unlock()
 		return nil, errors.New("table teu kapanggih")
 	}
+
+	return &fileIterator{
+		rowReader: newRowReader(file, filepath.Base(path)),
+		file:      file,
+		unlock:    unlock,
+	}, nil
+}
+
+const (
+	formatEmpty = iota
+	formatLegacy
+	formatV2
+)
+
+// fileFormat ningali baris kahiji file tabel.
+func fileFormat(path string) (int, error) {
+	file, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return formatEmpty, nil
+	}
+	if err != nil {
+		return 0, err
+	}
 	defer file.Close()
 
-	var rows []string
-	sc := bufio.NewScanner(file)
-	for sc.Scan() {
-		rows = append(rows, sc.Text())
+	first, err := bufio.NewReader(file).ReadString('\n')
+	if first == "" && err != nil {
+		return formatEmpty, nil
+	}
+	if strings.TrimRight(first, "\r\n") == FileHeader {
+		return formatV2, nil
 	}
+	return formatLegacy, nil
+}
+
+// maxRowSize nyaeta panjang maksimal hiji baris (TEXT panjang)
+const maxRowSize = 16 * 1024 * 1024
 
-	return rows, nil
+// rowReader maca file tabel baris-baris; ngarti format v2 jeung format heubeul.
+type rowReader struct {
+	sc     *bufio.Scanner
+	name   string
+	lineNo int
+	v2     bool
+	row    []string
+	err    error
+
+	read   int64 // byte nu geus dikonsumsi scanner
+	offset int64 // offset baris ayeuna dina file
+}
+
+func newRowReader(f *os.File, name string) *rowReader {
+	r := &rowReader{name: name}
+	r.sc = bufio.NewScanner(f)
+	r.sc.Buffer(make([]byte, 64*1024), maxRowSize)
+	r.sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := bufio.ScanLines(data, atEOF)
+		if token != nil {
+			r.offset = r.read
+		}
+		r.read += int64(advance)
+		return advance, token, err
+	})
+	return r
+}
+
+func (r *rowReader) Next() bool {
+	if r.err != nil {
+		return false
+	}
+
+	for r.sc.Scan() {
+		r.lineNo++
+		line := r.sc.Text()
+
+		if r.lineNo == 1 && line == FileHeader {
+			r.v2 = true
+			continue
+		}
+		if line == "" {
+			continue
+		}
+
+		if !r.v2 {
+			r.row = decodeLegacy(line)
+			return true
+		}
+
+		row, err := DecodeRow(line)
+		if err != nil {
+			r.err = fmt.Errorf("%s baris %d: %v", r.name, r.lineNo, err)
+			return false
+		}
+		r.row = row
+		return true
+	}
+
+	r.err = r.sc.Err()
+	return false
+}
+
+func (r *rowReader) Row() []string { return r.row }
+
+// Offset mulangkeun posisi byte baris ayeuna (dipaké salaku ID baris indeks).
+func (r *rowReader) Offset() int64 { return r.offset }
+
+func (r *rowReader) Err() error { return r.err }
+
+type fileIterator struct {
+	*rowReader
+	file   *os.File
+	unlock func()
+}
+
+func (it *fileIterator) Close() error {
+	if it.unlock == nil {
+		return nil
+	}
+	it.unlock()
+	it.unlock = nil
+	return it.file.Close()
 }
 
 func initDefaultUser(systemPath string) error {
@@ -140,27 +366,182 @@ func initDefaultUser(systemPath string) error {
 		"*",
 	}, "|") + "\n"
 
-	return os.WriteFile(userFile, []byte(line), 0644)
+	return fsutil.WriteFile(userFile, []byte(line), 0644)
 }
 
+// =======================
+// REWRITE DATA
+// =======================
+
+type rewriteFunc func(row []string) (out []string, keep bool, err error)
 
-func Rewrite(table string, rows []string) error {
-	u, err := auth.CurrentUser()
+// rewriteRows ngaliwatkeun unggal baris ka fn sarta nulis hasilna ka file
+// samentawis (streaming, teu dimuat sakaligus). fn mulangkeun keep=false
+// pikeun miceun baris. Sakabéh prosésna dina konci exclusive, jadi SIMPEN
+// ti klien séjén teu leungit di tengah-tengah OMEAN / MICEUN.
+func rewriteRows(database, table string, fn rewriteFunc) error {
+	return upsertRows(database, table, fn, nil)
+}
+
+// upsertRows nyaéta rewriteRows nu nambahkeun baris ti rest di tungtung
+// file, dina konci nu sarua.
+func upsertRows(database, table string, fn rewriteFunc, rest RestFunc) error {
+	if database == "" {
+		return errors.New("can use database heula")
+	}
+
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+
+	path, err := tablePath(database, table)
+	if err != nil {
+		return err
+	}
+
+	unlock, err := lockTable(database, table, true)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	// tabel can boga file: teu aya nu dirobah, baris ti rest di-append
+	if rest != nil {
+		if _, err := os.Stat(path); errors.Is(err, os.ErrNotExist) {
+			rows, err := rest()
+			if err != nil || len(rows) == 0 {
+				return err
+			}
+			p, err := prepareAppend(database, table, path, formatEmpty, rows)
+			if err != nil {
+				return err
+			}
+			return runPending([]*pendingWrite{p})
+		}
+	}
+
+	p, err := prepareRewrite(database, path, fn, rest)
 	if err != nil {
 		return err
 	}
-	
-	path, err := tablePath(u.Database, table)
+	return runPending([]*pendingWrite{p})
+}
+
+// rewriteLocked nyaéta rewriteRows tanpa nyekel konci (nu manggil geus nyekel).
+func rewriteLocked(database, path string, fn rewriteFunc) error {
+	p, err := prepareRewrite(database, path, fn, nil)
 	if err != nil {
 		return err
 	}
+	return runPending([]*pendingWrite{p})
+}
+
+// prepareRewrite nulis hasil fn (ditambah baris ti extra di tukang) kana
+// file samentawis, tapi can ngaganti file tabel.
+func prepareRewrite(database, path string, fn rewriteFunc, extra RestFunc) (*pendingWrite, error) {
+	src, err := os.Open(path)
+	if err != nil {
+		return nil, errors.New("table teu kapanggih")
+	}
+	defer src.Close()
 
-	content := strings.Join(rows, "\n")
-	
-	
-	if len(rows) > 0 {
-		content += "\n" 
+	baseSize, baseMod := fileStamp(path)
+
+	tmp, err := fsutil.Create(path, 0644)
+	if err != nil {
+		return nil, err
 	}
 
-	return os.WriteFile(path, []byte(content), 0644)
-}
\ No newline at end of file
+	// indeks diwangun deui bari nulis, make offset baris dina file anyar
+	infos, err := listIndexes(database, tableName(path))
+	if err != nil {
+		tmp.Abort()
+		return nil, err
+	}
+	indexes := make([]*tableIndex, len(infos))
+	for i, info := range infos {
+		indexes[i] = &tableIndex{
+			path:    indexPath(database, tableName(path), info.Name),
+			info:    info,
+			tree:    newIndexTree(info),
+			overlay: newIndexTree(info),
+		}
+	}
+
+	w := bufio.NewWriter(tmp)
+	w.WriteString(FileHeader + "\n")
+	off := int64(len(FileHeader) + 1)
+
+	write := func(row []string) {
+		line := EncodeRow(row) + "\n"
+		w.WriteString(line)
+		for _, ti := range indexes {
+			ti.tree.Insert(indexKey(row, ti.info.Pos), off)
+		}
+		off += int64(len(line))
+	}
+
+	r := newRowReader(src, filepath.Base(path))
+	for r.Next() {
+		row, keep, err := fn(r.Row())
+		if err != nil {
+			tmp.Abort()
+			return nil, err
+		}
+		if keep {
+			write(row)
+		}
+	}
+	if err := r.Err(); err != nil {
+		tmp.Abort()
+		return nil, err
+	}
+	if extra != nil {
+		rows, err := extra()
+		if err != nil {
+			tmp.Abort()
+			return nil, err
+		}
+		for _, row := range rows {
+			write(row)
+		}
+	}
+
+	if err := w.Flush(); err != nil {
+		tmp.Abort()
+		return nil, err
+	}
+
+	if err := tmp.Prepare(); err != nil {
+		tmp.Abort()
+		return nil, err
+	}
+
+	finish := func(walOp) {
+		size, mod := fileStamp(path)
+		for _, ti := range indexes {
+			ti.size, ti.mod = size, mod
+			// tabel geus robah; indeks nu gagal ditulis bakal diwangun deui
+			_ = writeIndex(ti)
+		}
+	}
+
+	return &pendingWrite{
+		op: walOp{
+			Kind:     walOpRewrite,
+			Database: database,
+			File:     filepath.Base(path),
+			Temp:     filepath.Base(tmp.Name()),
+			BaseSize: baseSize,
+			BaseMod:  baseMod,
+		},
+		finish: finish,
+		abort:  tmp.Abort,
+	}, nil
+}
+
+// tableName mulangkeun ngaran tabel tina path file tabel.
+func tableName(path string) string {
+	base := filepath.Base(path)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/febrd/maungdb/internal/config"
+)
+
+// =======================
+// TABLE LOCKING
+// =======================
+//
+// Unggal tabel boga dua lapis konci:
+//  1. sync.RWMutex dina prosés ieu (loba nu maca, hiji nu nulis)
+//  2. advisory file lock (<tabel>.lock) supaya CLI jeung server nu
+//     ngajalankeun maung_data nu sarua teu silih tindih.
+
+type lockManager struct {
+	mu    sync.Mutex
+	locks map[string]*sync.RWMutex
+}
+
+var tableLocks = &lockManager{locks: make(map[string]*sync.RWMutex)}
+
+func (m *lockManager) get(key string) *sync.RWMutex {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	l, ok := m.locks[key]
+	if !ok {
+		l = &sync.RWMutex{}
+		m.locks[key] = l
+	}
+	return l
+}
+
+func lockFilePath(database, table string) string {
+	return filepath.Join(DatabasePath(database), table+".lock")
+}
+
+// lockTable nyekel konci tabel. exclusive=true pikeun nu nulis.
+// Hasilna fungsi pikeun ngaleupaskeun konci. Konci shared teu nyieun
+// <tabel>.lock pikeun tabel nu can aya, supaya salah ketik ngaran tabel
+// teu ninggalkeun file dina diréktori database.
+func lockTable(database, table string, exclusive bool) (func(), error) {
+	l := tableLocks.get(database + "/" + table)
+	if exclusive {
+		l.Lock()
+	} else {
+		l.RLock()
+	}
+
+	release := func() {
+		if exclusive {
+			l.Unlock()
+		} else {
+			l.RUnlock()
+		}
+	}
+
+	flags := os.O_CREATE | os.O_RDWR
+	if !exclusive {
+		flags = os.O_RDWR
+	}
+	f, err := os.OpenFile(lockFilePath(database, table), flags, 0644)
+	if err != nil && !exclusive && os.IsNotExist(err) {
+		if !tableFileExists(database, table) {
+			// euweuh nu bisa dibaca, cukup konci dina prosés ieu
+			return release, nil
+		}
+		f, err = os.OpenFile(lockFilePath(database, table), os.O_CREATE|os.O_RDWR, 0644)
+	}
+	if err != nil {
+		release()
+		return nil, err
+	}
+
+	if err := flock(f, exclusive); err != nil {
+		f.Close()
+		release()
+		return nil, err
+	}
+
+	return func() {
+		_ = funlock(f)
+		f.Close()
+		release()
+	}, nil
+}
+
+func tableFileExists(database, table string) bool {
+	for _, ext := range config.AllowedExt {
+		if _, err := os.Stat(filepath.Join(DatabasePath(database), table+ext)); err == nil {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,380 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/febrd/maungdb/engine/index"
+)
+
+// MemoryEngine nyimpen baris dina RAM wungkul. Data leungit basa prosés
+// eureun; cocog pikeun `maung server --memory` (demo, kelas) jeung tés.
+type MemoryEngine struct {
+	mu     sync.Mutex
+	tables map[string]*memTable
+}
+
+func NewMemoryEngine() *MemoryEngine {
+	return &MemoryEngine{tables: make(map[string]*memTable)}
+}
+
+func (e *MemoryEngine) Open(database, table string) (Table, error) {
+	if database == "" {
+		return nil, errors.New("can use database heula")
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	key := database + "/" + table
+	t, ok := e.tables[key]
+	if !ok {
+		t = &memTable{}
+		e.tables[key] = t
+	}
+	return t, nil
+}
+
+func (e *MemoryEngine) Drop(database, table string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	delete(e.tables, database+"/"+table)
+	return nil
+}
+
+func (e *MemoryEngine) Rename(database, table, newDatabase, newTable string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	from, to := database+"/"+table, newDatabase+"/"+newTable
+	if from == to {
+		return nil
+	}
+	if t, ok := e.tables[to]; ok {
+		t.mu.RLock()
+		n := len(t.rows)
+		t.mu.RUnlock()
+		if n > 0 {
+			return fmt.Errorf("tabel '%s' geus boga data", newTable)
+		}
+	}
+	if t, ok := e.tables[from]; ok {
+		e.tables[to] = t
+		delete(e.tables, from)
+	} else {
+		delete(e.tables, to)
+	}
+	return nil
+}
+
+// RenameDatabase: schema tetep dina disk, jadi diréktorina ogé diganti.
+func (e *MemoryEngine) RenameDatabase(database, newDatabase string) error {
+	if err := renameDatabaseDir(database, newDatabase); err != nil {
+		return err
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var keys []string
+	for key := range e.tables {
+		if strings.HasPrefix(key, database+"/") {
+			keys = append(keys, key)
+		}
+	}
+	for _, key := range keys {
+		e.tables[newDatabase+"/"+strings.TrimPrefix(key, database+"/")] = e.tables[key]
+		delete(e.tables, key)
+	}
+	return nil
+}
+
+// Commit ngonci sakabéh tabel (urutan ngaran), mariksa versi, ngitung
+// baris anyar unggal tabel, tuluy kakara ngaganti sakabéhna.
+func (e *MemoryEngine) Commit(writes []TableWrites) error {
+	writes = sortWrites(writes)
+
+	tables := make([]*memTable, len(writes))
+	for i, w := range writes {
+		t, err := e.Open(w.Database, w.Table)
+		if err != nil {
+			return err
+		}
+		tables[i] = t.(*memTable)
+		tables[i].mu.Lock()
+		defer tables[i].mu.Unlock()
+	}
+
+	results := make([][][]string, len(writes))
+	for i, w := range writes {
+		t := tables[i]
+		if w.Version != "" && t.versionLocked() != w.Version {
+			return conflictError(w)
+		}
+		if len(w.Ops) == 0 {
+			continue
+		}
+
+		out := make([][]string, 0, len(t.rows))
+		for _, row := range t.rows {
+			row, keep, err := applyWrites(copyRow(row), w.Ops)
+			if err != nil {
+				return err
+			}
+			if keep {
+				out = append(out, row)
+			}
+		}
+		extra, err := insertedRows(w.Ops)
+		if err != nil {
+			return err
+		}
+		results[i] = append(out, extra...)
+	}
+
+	for i, w := range writes {
+		if len(w.Ops) == 0 {
+			continue
+		}
+		t := tables[i]
+		t.rows = results[i]
+		t.version++
+		t.rebuildIndexes()
+	}
+	return nil
+}
+
+type memTable struct {
+	mu      sync.RWMutex
+	rows    [][]string
+	indexes []*memIndex
+	version int64
+}
+
+// memIndex: ID baris = posisi dina t.rows.
+type memIndex struct {
+	info IndexInfo
+	tree *index.BTree
+}
+
+func (t *memTable) Scan() (RowIterator, error) {
+	// t.rows teu kungsi dirobah di tempat (Update/Delete nyieun slice anyar,
+	// Insert ngan nambahan di tukang), jadi snapshot header slice geus cukup.
+	t.mu.RLock()
+	rows := t.rows
+	t.mu.RUnlock()
+
+	return &memIterator{rows: rows, pos: -1}, nil
+}
+
+type memIterator struct {
+	rows [][]string
+	pos  int
+}
+
+func (it *memIterator) Next() bool {
+	it.pos++
+	return it.pos < len(it.rows)
+}
+
+func (it *memIterator) Row() []string { return copyRow(it.rows[it.pos]) }
+
+func (it *memIterator) Err() error { return nil }
+
+func (it *memIterator) Close() error { return nil }
+
+func (t *memTable) Insert(rows ...[]string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, row := range rows {
+		for _, ix := range t.indexes {
+			ix.tree.Insert(indexKey(row, ix.info.Pos), int64(len(t.rows)))
+		}
+		t.rows = append(t.rows, copyRow(row))
+	}
+	t.version++
+	return nil
+}
+
+func (t *memTable) Update(fn UpdateFunc) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([][]string, len(t.rows))
+	count := 0
+	for i, row := range t.rows {
+		newRow, ok, err := fn(copyRow(row))
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			row = copyRow(newRow)
+			count++
+		}
+		out[i] = row
+	}
+
+	t.rows = out
+	t.version++
+	t.rebuildIndexes()
+	return count, nil
+}
+
+func (t *memTable) Upsert(fn UpdateFunc, rest RestFunc) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([][]string, len(t.rows))
+	count := 0
+	for i, row := range t.rows {
+		newRow, ok, err := fn(copyRow(row))
+		if err != nil {
+			return 0, err
+		}
+		if ok {
+			row = copyRow(newRow)
+			count++
+		}
+		out[i] = row
+	}
+
+	extra, err := rest()
+	if err != nil {
+		return 0, err
+	}
+	for _, row := range extra {
+		out = append(out, copyRow(row))
+	}
+
+	t.rows = out
+	t.version++
+	t.rebuildIndexes()
+	return count, nil
+}
+
+func (t *memTable) Delete(fn MatchFunc) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	out := make([][]string, 0, len(t.rows))
+	count := 0
+	for _, row := range t.rows {
+		hit, err := fn(copyRow(row))
+		if err != nil {
+			return 0, err
+		}
+		if hit {
+			count++
+			continue
+		}
+		out = append(out, row)
+	}
+
+	t.rows = out
+	t.version++
+	t.rebuildIndexes()
+	return count, nil
+}
+
+// rebuildIndexes ngawangun deui sakabéh indeks (posisi baris robah).
+func (t *memTable) rebuildIndexes() {
+	for _, ix := range t.indexes {
+		ix.tree = newIndexTree(ix.info)
+		for id, row := range t.rows {
+			ix.tree.Insert(indexKey(row, ix.info.Pos), int64(id))
+		}
+	}
+}
+
+func (t *memTable) CreateIndex(info IndexInfo) error {
+	if !validIndexName(info.Name) {
+		return errors.New("ngaran indeks teu valid")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, ix := range t.indexes {
+		if ix.info.Name == info.Name {
+			return fmt.Errorf("indeks '%s' geus aya", info.Name)
+		}
+	}
+
+	ix := &memIndex{info: info, tree: newIndexTree(info)}
+	for id, row := range t.rows {
+		ix.tree.Insert(indexKey(row, info.Pos), int64(id))
+	}
+	t.indexes = append(t.indexes, ix)
+	return nil
+}
+
+func (t *memTable) Indexes() ([]IndexInfo, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	infos := make([]IndexInfo, len(t.indexes))
+	for i, ix := range t.indexes {
+		infos[i] = ix.info
+	}
+	return infos, nil
+}
+
+// IndexScan nyekel RLock nepi ka Close, sabab Insert ngarobah tangkal di tempat.
+func (t *memTable) IndexScan(name string, r KeyRange) (RowIterator, error) {
+	t.mu.RLock()
+
+	for _, ix := range t.indexes {
+		if ix.info.Name == name {
+			return &memIndexIterator{t: t, cursor: ordered(ix.tree, cursorFor(ix.tree, r), r)}, nil
+		}
+	}
+
+	t.mu.RUnlock()
+	return nil, fmt.Errorf("indeks '%s' teu kapanggih", name)
+}
+
+type memIndexIterator struct {
+	t      *memTable
+	cursor index.Iterator
+	row    []string
+	closed bool
+}
+
+func (it *memIndexIterator) Next() bool {
+	if it.closed || !it.cursor.Next() {
+		return false
+	}
+	it.row = copyRow(it.t.rows[it.cursor.Item().ID])
+	return true
+}
+
+func (it *memIndexIterator) Row() []string { return it.row }
+
+func (it *memIndexIterator) Err() error { return it.cursor.Err() }
+
+func (it *memIndexIterator) Close() error {
+	if !it.closed {
+		it.closed = true
+		it.t.mu.RUnlock()
+	}
+	return nil
+}
+
+func (t *memTable) Version() (string, error) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	return t.versionLocked(), nil
+}
+
+func (t *memTable) versionLocked() string {
+	return strconv.FormatInt(t.version, 10)
+}
+
+func copyRow(row []string) []string {
+	return append([]string(nil), row...)
+}
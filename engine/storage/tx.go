@@ -0,0 +1,254 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// =======================
+// TRANSAKSI
+// =======================
+//
+// Transaksi (MIMITIAN ... ANGGEUSAN) teu nulis langsung: SIMPEN / OMEAN /
+// MICEUN dikumpulkeun heula salaku WriteOp. Basa ANGGEUSAN, Engine.Commit
+// nerapkeun sakabéh parobahan sakaligus. Saméméhna, versi unggal tabel nu
+// kungsi dibaca dibandingkeun jeung versi ayeuna; lamun geus dirobah ku sesi
+// séjén, transaksi ditolak (ErrConflict) tibatan numpes parobahan batur.
+
+// ErrConflict: tabel geus dirobah ku sesi séjén saprak dibaca ku transaksi.
+var ErrConflict = errors.New("transaksi bentrok")
+
+// WriteOp nyaeta hiji parobahan nu can di-commit. Ngan salah sahiji field
+// nu dieusian.
+type WriteOp struct {
+	Insert [][]string
+	Update UpdateFunc
+	Delete MatchFunc
+}
+
+// TableWrites nyaeta parobahan hiji tabel dina transaksi, saurutan. Unggal
+// tabel ngan meunang muncul sakali dina hiji Commit.
+// Version (lamun teu kosong) kudu sarua jeung Table.Version basa commit.
+type TableWrites struct {
+	Database string
+	Table    string
+	Version  string
+	Ops      []WriteOp
+}
+
+func (w TableWrites) key() string { return w.Database + "/" + w.Table }
+
+// sortWrites ngurutkeun tabel supaya konci salawasna dicekel dina urutan
+// nu sarua (teu deadlock antara dua commit).
+func sortWrites(writes []TableWrites) []TableWrites {
+	out := append([]TableWrites(nil), writes...)
+	sort.Slice(out, func(i, j int) bool { return out[i].key() < out[j].key() })
+	return out
+}
+
+func conflictError(w TableWrites) error {
+	return fmt.Errorf("%w: tabel '%s' geus dirobah ku sesi séjén, transaksi dibatalkeun", ErrConflict, w.Table)
+}
+
+// applyWrites ngaliwatkeun hiji baris ka ops (SIMPEN dilewat).
+func applyWrites(row []string, ops []WriteOp) ([]string, bool, error) {
+	for _, op := range ops {
+		switch {
+		case op.Update != nil:
+			out, ok, err := op.Update(copyRow(row))
+			if err != nil {
+				return nil, false, err
+			}
+			if ok {
+				row = out
+			}
+		case op.Delete != nil:
+			hit, err := op.Delete(copyRow(row))
+			if err != nil {
+				return nil, false, err
+			}
+			if hit {
+				return nil, false, nil
+			}
+		}
+	}
+	return row, true, nil
+}
+
+// insertedRows mulangkeun baris nu di-SIMPEN ku ops, sanggeus dikenaan op
+// nu datang saterusna.
+func insertedRows(ops []WriteOp) ([][]string, error) {
+	var rows [][]string
+	for i, op := range ops {
+		for _, row := range op.Insert {
+			out, keep, err := applyWrites(row, ops[i+1:])
+			if err != nil {
+				return nil, err
+			}
+			if keep {
+				rows = append(rows, copyRow(out))
+			}
+		}
+	}
+	return rows, nil
+}
+
+func onlyInserts(ops []WriteOp) bool {
+	for _, op := range ops {
+		if op.Insert == nil {
+			return false
+		}
+	}
+	return true
+}
+
+// ApplyWrites mulangkeun iterator baris base sanggeus dikenaan ops, jadi
+// transaksi bisa maca parobahanana sorangan saméméh ANGGEUSAN.
+func ApplyWrites(base RowIterator, ops []WriteOp) RowIterator {
+	return &writesIterator{base: base, ops: ops}
+}
+
+type writesIterator struct {
+	base  RowIterator
+	ops   []WriteOp
+	extra [][]string
+	tail  bool
+	row   []string
+	err   error
+}
+
+func (it *writesIterator) Next() bool {
+	if it.err != nil {
+		return false
+	}
+
+	for !it.tail && it.base.Next() {
+		row, keep, err := applyWrites(it.base.Row(), it.ops)
+		if err != nil {
+			it.err = err
+			return false
+		}
+		if keep {
+			it.row = row
+			return true
+		}
+	}
+
+	if !it.tail {
+		if it.err = it.base.Err(); it.err != nil {
+			return false
+		}
+		it.tail = true
+		it.extra, it.err = insertedRows(it.ops)
+		if it.err != nil {
+			return false
+		}
+	}
+
+	if len(it.extra) == 0 {
+		return false
+	}
+	it.row, it.extra = it.extra[0], it.extra[1:]
+	return true
+}
+
+func (it *writesIterator) Row() []string { return it.row }
+
+func (it *writesIterator) Err() error { return it.err }
+
+func (it *writesIterator) Close() error { return it.base.Close() }
+
+// =======================
+// COMMIT (FILE)
+// =======================
+
+func fileVersion(path string) string {
+	size, mod := fileStamp(path)
+	return fmt.Sprintf("%d:%d", size, mod)
+}
+
+// commitWrites nerapkeun parobahan sababaraha tabel dina hiji catetan WAL.
+// Sakabéh tabel dikonci exclusive (tabel nu ngan dibaca: shared) salila
+// mariksa versi & nulis.
+func commitWrites(writes []TableWrites) error {
+	if err := ensureRecovered(); err != nil {
+		return err
+	}
+
+	writes = sortWrites(writes)
+
+	for _, w := range writes {
+		if w.Database == "" {
+			return errors.New("can use database heula")
+		}
+		unlock, err := lockTable(w.Database, w.Table, len(w.Ops) > 0)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	var pending []*pendingWrite
+	abort := func() {
+		for _, p := range pending {
+			p.abort()
+		}
+	}
+
+	for _, w := range writes {
+		path, err := tablePath(w.Database, w.Table)
+		if err != nil {
+			abort()
+			return err
+		}
+		if w.Version != "" && fileVersion(path) != w.Version {
+			abort()
+			return conflictError(w)
+		}
+		if len(w.Ops) == 0 {
+			continue
+		}
+
+		p, err := prepareWrites(w, path)
+		if err != nil {
+			abort()
+			return err
+		}
+		if p != nil {
+			pending = append(pending, p)
+		}
+	}
+
+	if len(pending) == 0 {
+		return nil
+	}
+	return runPending(pending)
+}
+
+// prepareWrites milih append (ngan SIMPEN, atawa tabel can aya) atawa
+// rewrite (aya OMEAN / MICEUN).
+func prepareWrites(w TableWrites, path string) (*pendingWrite, error) {
+	format, err := fileFormat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := insertedRows(w.Ops)
+	if err != nil {
+		return nil, err
+	}
+
+	size, _ := fileStamp(path)
+	if format != formatLegacy && (onlyInserts(w.Ops) || size <= 0) {
+		if len(rows) == 0 {
+			return nil, nil
+		}
+		return prepareAppend(w.Database, w.Table, path, format, rows)
+	}
+
+	fn := func(row []string) ([]string, bool, error) {
+		return applyWrites(row, w.Ops)
+	}
+	return prepareRewrite(w.Database, path, fn, func() ([][]string, error) { return rows, nil })
+}
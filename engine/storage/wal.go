@@ -0,0 +1,561 @@
+package storage
+
+import (
+	"bufio"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
+)
+
+// =======================
+// WRITE-AHEAD LOG
+// =======================
+//
+// Unggal SIMPEN / OMEAN / MICEUN dicatet heula dina _system/wal.maung
+// (di-fsync) saméméh file tabel dirobah. Lamun listrik pareum di tengah
+// jalan, Init bakal ngalengkepan (replay) atawa ngabatalkeun (rollback)
+// catetan nu teu acan ditandaan anggeus.
+//
+// Hiji catetan (walRecord) eusina hiji atawa leuwih walOp:
+//   - append : ukuran file saméméh append + baris anyar. Replay motong
+//              file ka ukuran éta heula, jadi aman diulang.
+//   - rewrite: ngaran file samentawis (geus di-fsync) nu bakal ngaganti
+//              file tabel. Replay ngan saukur ngalengkepan rename.
+//
+// Catetan nu eusina sababaraha op (ANGGEUSAN transaksi) diterapkeun boh
+// kabéh boh euweuh.
+//
+// Replay ngan dilakukeun lamun file tabel masih dina kaayaan saméméh op
+// (ukuran / waktu robah sarua), supaya catetan basi ti prosés nu maot teu
+// numpes data nu ditulis sanggeusna.
+
+const (
+	walFile     = "wal.maung"
+	walLockFile = "wal.lock"
+
+	// walCheckpointSize: log dikosongkeun deui lamun geus leuwih ti ieu
+	walCheckpointSize = 1 << 20
+)
+
+const (
+	walOpAppend  = "append"
+	walOpRewrite = "rewrite"
+
+	walStatusCommit = "commit"
+	walStatusAbort  = "abort"
+)
+
+type walOp struct {
+	Kind     string `json:"kind"`
+	Database string `json:"db"`
+	File     string `json:"file"`
+	Offset   int64  `json:"offset,omitempty"`
+	Data     string `json:"data,omitempty"`
+	Temp     string `json:"temp,omitempty"`
+
+	// kaayaan file tabel saméméh rewrite (-1 = can aya)
+	BaseSize int64 `json:"base_size,omitempty"`
+	BaseMod  int64 `json:"base_mod,omitempty"`
+}
+
+type walRecord struct {
+	ID     string  `json:"id"`
+	Ops    []walOp `json:"ops,omitempty"`
+	Status string  `json:"status,omitempty"`
+}
+
+type writeAheadLog struct {
+	mu        sync.Mutex   // nulis ka file log dina prosés ieu
+	gate      sync.RWMutex // op nyekel RLock; checkpoint & recovery nyekel Lock
+	recovered sync.Once
+}
+
+var wal = &writeAheadLog{}
+
+func walPath() string {
+	return filepath.Join(config.DataDir, config.SystemDir, walFile)
+}
+
+func walLockPath() string {
+	return filepath.Join(config.DataDir, config.SystemDir, walLockFile)
+}
+
+func (op walOp) path() string {
+	return filepath.Join(DatabasePath(op.Database), op.File)
+}
+
+func (op walOp) tempPath() string {
+	return filepath.Join(DatabasePath(op.Database), op.Temp)
+}
+
+// run nyatet ops kana log (fsync), nerapkeun, tuluy nandaan commit.
+// Lamun nerapkeun gagal, op nu geus diterapkeun dibalikkeun deui.
+func (w *writeAheadLog) run(ops []walOp) error {
+	if err := w.runOps(ops); err != nil {
+		return err
+	}
+
+	w.maybeCheckpoint()
+	return nil
+}
+
+func (w *writeAheadLog) runOps(ops []walOp) error {
+	w.gate.RLock()
+	defer w.gate.RUnlock()
+
+	lf, err := os.OpenFile(walLockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	if err := flock(lf, false); err != nil {
+		return err
+	}
+	defer funlock(lf)
+
+	// offset append diitung di dieu (bari nyekel konci WAL) supaya
+	// recovery ti prosés séjén teu bisa nyelap
+	for i := range ops {
+		if ops[i].Kind == walOpAppend {
+			ops[i].Offset, _ = fileStamp(ops[i].path())
+			if ops[i].Offset < 0 {
+				ops[i].Offset = 0
+			}
+		}
+	}
+
+	id, err := newRecordID()
+	if err != nil {
+		return err
+	}
+
+	backups, err := backupTables(id, ops)
+	if err != nil {
+		return err
+	}
+	defer removeBackups(backups)
+
+	if err := w.write(walRecord{ID: id, Ops: ops}); err != nil {
+		return err
+	}
+
+	for i, op := range ops {
+		if err := applyOp(op); err != nil {
+			for j := i; j >= 0; j-- {
+				undoOp(ops[j])
+				if backups[j] != "" {
+					_ = fsutil.Replace(backups[j], ops[j].path())
+				}
+			}
+			_ = w.write(walRecord{ID: id, Status: walStatusAbort})
+			return err
+		}
+	}
+
+	return w.write(walRecord{ID: id, Status: walStatusCommit})
+}
+
+// backupTables: lamun catetan eusina leuwih ti hiji op (ANGGEUSAN
+// transaksi), file tabel saméméh rewrite di-hard-link heula, supaya rewrite
+// nu geus kajadian bisa dibalikkeun lamun op saterusna gagal. Sésa backup
+// (crash) dipiceun ku removeStaleTemps basa Init.
+func backupTables(id string, ops []walOp) ([]string, error) {
+	backups := make([]string, len(ops))
+	if len(ops) < 2 {
+		return backups, nil
+	}
+
+	for i, op := range ops {
+		if op.Kind != walOpRewrite {
+			continue
+		}
+		b := op.path() + "." + id + fsutil.TempSuffix
+		if err := os.Link(op.path(), b); err != nil {
+			removeBackups(backups)
+			return nil, err
+		}
+		backups[i] = b
+	}
+	return backups, nil
+}
+
+func removeBackups(backups []string) {
+	for _, b := range backups {
+		if b != "" {
+			_ = os.Remove(b)
+		}
+	}
+}
+
+func (w *writeAheadLog) write(rec walRecord) error {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	f, err := os.OpenFile(walPath(), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return err
+	}
+	return f.Sync()
+}
+
+// maybeCheckpoint ngosongkeun log lamun geus gedé sarta euweuh op nu keur jalan.
+func (w *writeAheadLog) maybeCheckpoint() {
+	info, err := os.Stat(walPath())
+	if err != nil || info.Size() < walCheckpointSize {
+		return
+	}
+
+	if !w.gate.TryLock() {
+		return
+	}
+	defer w.gate.Unlock()
+
+	_ = w.checkpoint()
+}
+
+// checkpoint ngosongkeun WAL. Sakabéh op nu dicatet geus anggeus (commit)
+// sarta file tabelna geus di-fsync, jadi log teu diperlukeun deui.
+// Kudu dipanggil bari nyekel gate.Lock.
+func (w *writeAheadLog) checkpoint() error {
+	lf, err := os.OpenFile(walLockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	ok, err := tryFlock(lf, true)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return nil // prosés séjén keur nulis; engké deui
+	}
+	defer funlock(lf)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if err := os.Truncate(walPath(), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// ensureRecovered mastikeun recovery geus jalan sakali dina prosés ieu.
+// Prosés CLI teu ngaliwatan Init, jadi dipanggil ti unggal operasi tabel.
+func ensureRecovered() error {
+	var err error
+	wal.recovered.Do(func() { err = recoverWAL() })
+	return err
+}
+
+// recoverWAL dipanggil ku Init: ngalengkepan catetan nu can commit, tuluy
+// ngosongkeun log.
+func recoverWAL() error {
+	if _, err := os.Stat(filepath.Dir(walPath())); errors.Is(err, os.ErrNotExist) {
+		return nil // can di-init
+	}
+
+	wal.gate.Lock()
+	defer wal.gate.Unlock()
+
+	lf, err := os.OpenFile(walLockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	if err := flock(lf, true); err != nil {
+		return err
+	}
+	defer funlock(lf)
+
+	pending, err := readPending()
+	if err != nil {
+		return err
+	}
+
+	for _, rec := range pending {
+		for _, op := range rec.Ops {
+			if err := redoOp(op); err != nil {
+				return err
+			}
+		}
+	}
+
+	if err := os.Truncate(walPath(), 0); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	return nil
+}
+
+// removeStaleTemps miceun sésa file samentawis (crash) basa Init. Konci
+// WAL dicekel exclusive, jadi euweuh op prosés séjén nu keur jalan sarta
+// backup (backupTables) nu kapanggih téh sésa crash. Lamun masih aya
+// catetan nu can anggeus, backup-na bisa diperlukeun ku redo, jadi
+// beberesih dilewat. File samentawis tabel nu keur dikonci (rewrite keur
+// disiapkeun) ogé dilewat, kitu deui file samentawis séjén (schema, users,
+// grants) nu can kolot: nu nulisna teu nyekel konci WAL atawa konci tabel.
+func removeStaleTemps() error {
+	wal.gate.Lock()
+	defer wal.gate.Unlock()
+
+	lf, err := os.OpenFile(walLockPath(), os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return err
+	}
+	defer lf.Close()
+
+	if err := flock(lf, true); err != nil {
+		return err
+	}
+	defer funlock(lf)
+
+	pending, err := readPending()
+	if err != nil || len(pending) > 0 {
+		return err
+	}
+
+	_, err = fsutil.Recover(config.DataDir, tempInUse)
+	return err
+}
+
+// staleTempAge: file samentawis nu lain milik tabel kakara dianggap sésa
+// crash sanggeus umurna leuwih ti ieu.
+const staleTempAge = 10 * time.Minute
+
+// tempInUse: file samentawis tabel (data atawa indeks) nu tabelna (ngaran
+// saméméh '.' munggaran) keur dikonci ku prosés ieu atawa prosés séjén,
+// atawa file samentawis séjén nu umurna can staleTempAge.
+func tempInUse(path string) bool {
+	dir, name := filepath.Split(path)
+	database, ok := strings.CutPrefix(filepath.Base(filepath.Clean(dir)), "db_")
+	if !ok || filepath.Dir(filepath.Clean(dir)) != filepath.Clean(config.DataDir) || !isTableTemp(name) {
+		info, err := os.Stat(path)
+		return err != nil || time.Since(info.ModTime()) < staleTempAge
+	}
+	table, _, _ := strings.Cut(name, ".")
+
+	l := tableLocks.get(database + "/" + table)
+	if !l.TryLock() {
+		return true
+	}
+	defer l.Unlock()
+
+	f, err := os.OpenFile(lockFilePath(database, table), os.O_RDWR, 0644)
+	if err != nil {
+		return false // euweuh file konci: euweuh nu nyekel
+	}
+	defer f.Close()
+
+	free, err := tryFlock(f, true)
+	if err != nil || !free {
+		return true
+	}
+	_ = funlock(f)
+	return false
+}
+
+// isTableTemp: name (<file asli>.<acak>.maungtmp) asalna ti file data atawa
+// indeks tabel, lain ti schema.
+func isTableTemp(name string) bool {
+	orig := strings.TrimSuffix(name, fsutil.TempSuffix)
+	if i := strings.LastIndexByte(orig, '.'); i >= 0 {
+		orig = orig[:i]
+	}
+	if strings.HasSuffix(orig, indexExt) {
+		return true
+	}
+	for _, ext := range config.AllowedExt {
+		if strings.HasSuffix(orig, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// readPending mulangkeun catetan nu teu boga status commit/abort, saurutan.
+// Baris pamungkas nu rusak (crash basa nulis log) dianggap teu kungsi aya.
+func readPending() ([]walRecord, error) {
+	f, err := os.Open(walPath())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var order []string
+	records := make(map[string]walRecord)
+	finished := make(map[string]bool)
+
+	r := bufio.NewReader(f)
+	for {
+		line, err := r.ReadBytes('\n')
+		if len(line) > 0 && line[len(line)-1] == '\n' {
+			var rec walRecord
+			if json.Unmarshal(line, &rec) == nil && rec.ID != "" {
+				if rec.Status != "" {
+					finished[rec.ID] = true
+				} else {
+					records[rec.ID] = rec
+					order = append(order, rec.ID)
+				}
+			}
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var pending []walRecord
+	for _, id := range order {
+		if !finished[id] {
+			pending = append(pending, records[id])
+		}
+	}
+	return pending, nil
+}
+
+func applyOp(op walOp) error {
+	switch op.Kind {
+	case walOpAppend:
+		f, err := os.OpenFile(op.path(), os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		if _, err := f.WriteAt([]byte(op.Data), op.Offset); err != nil {
+			return err
+		}
+		return f.Sync()
+
+	case walOpRewrite:
+		return fsutil.Replace(op.tempPath(), op.path())
+	}
+	return errors.New("wal: op teu dikenal: " + op.Kind)
+}
+
+// redoOp nerapkeun deui op basa recovery. Aman diulang sababaraha kali.
+func redoOp(op walOp) error {
+	switch op.Kind {
+	case walOpAppend:
+		applied, err := appendState(op)
+		if err != nil || applied {
+			return err
+		}
+		if err := truncateTo(op.path(), op.Offset); err != nil {
+			return err
+		}
+		return applyOp(op)
+
+	case walOpRewrite:
+		if _, err := os.Stat(op.tempPath()); errors.Is(err, os.ErrNotExist) {
+			return nil // rename geus kajadian
+		}
+		size, mod := fileStamp(op.path())
+		if size != op.BaseSize || mod != op.BaseMod {
+			// tabel geus dirobah ku nu séjén; catetan ieu basi
+			return os.Remove(op.tempPath())
+		}
+		return applyOp(op)
+	}
+	return nil
+}
+
+// appendState mariksa naha append geus lengkep (applied) atawa basi.
+// Hasil false+nil hartina append kudu diulang ti Offset.
+func appendState(op walOp) (bool, error) {
+	size, _ := fileStamp(op.path())
+	end := op.Offset + int64(len(op.Data))
+	if size >= 0 && size < op.Offset {
+		return true, nil // tabel geus dirobah ku nu séjén (basi)
+	}
+	if size < end {
+		return false, nil
+	}
+
+	f, err := os.Open(op.path())
+	if err != nil {
+		return false, err
+	}
+	defer f.Close()
+
+	buf := make([]byte, len(op.Data))
+	if _, err := f.ReadAt(buf, op.Offset); err != nil {
+		return false, err
+	}
+
+	if string(buf) == op.Data || size > end {
+		// geus lengkep, atawa tabel geus dirobah ku nu séjén (basi)
+		return true, nil
+	}
+	return false, nil
+}
+
+// fileStamp mulangkeun ukuran & waktu robah file (-1, 0 lamun can aya).
+func fileStamp(path string) (int64, int64) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return -1, 0
+	}
+	return info.Size(), info.ModTime().UnixNano()
+}
+
+// undoOp ngabalikkeun op nu gagal di tengah jalan.
+func undoOp(op walOp) {
+	switch op.Kind {
+	case walOpAppend:
+		_ = truncateTo(op.path(), op.Offset)
+	case walOpRewrite:
+		_ = os.Remove(op.tempPath())
+	}
+}
+
+func truncateTo(path string, size int64) error {
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Size() <= size {
+		return nil
+	}
+	return os.Truncate(path, size)
+}
+
+func newRecordID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf), nil
+}
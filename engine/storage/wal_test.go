@@ -0,0 +1,177 @@
+package storage
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/febrd/maungdb/internal/config"
+	"github.com/febrd/maungdb/internal/fsutil"
+)
+
+func TestRemoveStaleTemps(t *testing.T) {
+	testData(t)
+
+	write := func(path string, age time.Duration) string {
+		t.Helper()
+		if err := os.WriteFile(path, []byte("x"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		when := time.Now().Add(-age)
+		if err := os.Chtimes(path, when, when); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	system := filepath.Join(config.DataDir, config.SystemDir)
+	db := DatabasePath("a")
+
+	fresh := []string{
+		write(filepath.Join(system, "users.maung.1"+fsutil.TempSuffix), 0),
+		write(filepath.Join(db, "peg.schema.2"+fsutil.TempSuffix), time.Minute),
+	}
+	stale := []string{
+		write(filepath.Join(system, "users.maung.3"+fsutil.TempSuffix), time.Hour),
+		write(filepath.Join(db, "peg.schema.4"+fsutil.TempSuffix), time.Hour),
+		write(filepath.Join(db, "peg.mg.5"+fsutil.TempSuffix), 0),
+		write(filepath.Join(db, "peg.ix_umur.idx.6"+fsutil.TempSuffix), 0),
+	}
+
+	if err := removeStaleTemps(); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range fresh {
+		if _, err := os.Stat(p); err != nil {
+			t.Errorf("%s dipiceun padahal can kolot", filepath.Base(p))
+		}
+	}
+	for _, p := range stale {
+		if _, err := os.Stat(p); err == nil {
+			t.Errorf("%s teu dipiceun", filepath.Base(p))
+		}
+	}
+
+	// file samentawis tabel nu keur dikonci dilewat
+	busy := write(filepath.Join(db, "peg.mg.7"+fsutil.TempSuffix), 0)
+	unlock, err := lockTable("a", "peg", true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+	if err := removeStaleTemps(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(busy); err != nil {
+		t.Error("file samentawis tabel nu dikonci dipiceun")
+	}
+}
+
+// tableRows maca sakabéh baris tabel.
+func tableRows(t *testing.T, database, table string) [][]string {
+	t.Helper()
+	it, err := scanRows(database, table)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer it.Close()
+	var rows [][]string
+	for it.Next() {
+		rows = append(rows, it.Row())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatal(err)
+	}
+	return rows
+}
+
+func TestRecoverWALAfterCrash(t *testing.T) {
+	testData(t)
+	tbl, err := NewFileEngine().Open("a", "peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tbl.Insert([]string{"1", "Asep"}, []string{"2", "Siti"}); err != nil {
+		t.Fatal(err)
+	}
+	path, err := tablePath("a", "peg")
+	if err != nil {
+		t.Fatal(err)
+	}
+	file := filepath.Base(path)
+
+	// append nu eureun di tengah jalan: ngan satengah baris nu kaburu ditulis
+	size, _ := fileStamp(path)
+	data := EncodeRow([]string{"3", "Dadang | Jr"}) + "\n"
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.WriteString(data[:len(data)/2]); err != nil {
+		t.Fatal(err)
+	}
+	f.Close()
+	op := walOp{Kind: walOpAppend, Database: "a", File: file, Offset: size, Data: data}
+	if err := wal.write(walRecord{ID: "1", Ops: []walOp{op}}); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := recoverWAL(); err != nil {
+		t.Fatal(err)
+	}
+	want := [][]string{{"1", "Asep"}, {"2", "Siti"}, {"3", "Dadang | Jr"}}
+	if got := tableRows(t, "a", "peg"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("sanggeus replay append: %q", got)
+	}
+	if info, err := os.Stat(walPath()); err != nil || info.Size() != 0 {
+		t.Errorf("WAL teu dikosongkeun: %v", err)
+	}
+
+	// rewrite nu file samentawisna geus siap tapi can di-rename
+	rewrite := func(id string, rows ...[]string) string {
+		t.Helper()
+		content := FileHeader + "\n"
+		for _, r := range rows {
+			content += EncodeRow(r) + "\n"
+		}
+		temp := file + "." + id + fsutil.TempSuffix
+		if err := os.WriteFile(filepath.Join(DatabasePath("a"), temp), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+		size, mod := fileStamp(path)
+		op := walOp{Kind: walOpRewrite, Database: "a", File: file, Temp: temp, BaseSize: size, BaseMod: mod}
+		if err := wal.write(walRecord{ID: id, Ops: []walOp{op}}); err != nil {
+			t.Fatal(err)
+		}
+		return filepath.Join(DatabasePath("a"), temp)
+	}
+
+	temp := rewrite("2", []string{"2", "Siti Aminah"})
+	if err := recoverWAL(); err != nil {
+		t.Fatal(err)
+	}
+	want = [][]string{{"2", "Siti Aminah"}}
+	if got := tableRows(t, "a", "peg"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("sanggeus replay rewrite: %q", got)
+	}
+	if _, err := os.Stat(temp); err == nil {
+		t.Error("file samentawis rewrite masih aya")
+	}
+
+	// catetan basi: tabel geus robah sanggeus catetan ditulis
+	temp = rewrite("3", []string{"9", "basi"})
+	if err := tbl.Insert([]string{"4", "Ujang"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := recoverWAL(); err != nil {
+		t.Fatal(err)
+	}
+	want = [][]string{{"2", "Siti Aminah"}, {"4", "Ujang"}}
+	if got := tableRows(t, "a", "peg"); !slices.EqualFunc(got, want, slices.Equal) {
+		t.Fatalf("catetan basi diterapkeun: %q", got)
+	}
+	if _, err := os.Stat(temp); err == nil {
+		t.Error("file samentawis catetan basi teu dipiceun")
+	}
+}
@@ -1,5 +1,7 @@
 package config
 
+import "time"
+
 var (
 	DataDir   = "maung_data"
 	SystemDir = "_system"
@@ -19,4 +21,8 @@ var (
 
 	SessionFile = "session.maung"
 	GrantsFile  = "grants.maung"
+
+	// Session server (per klien)
+	SessionCookie = "maung_session"
+	SessionTTL    = 12 * time.Hour
 )
@@ -0,0 +1,155 @@
+package fsutil
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// TempSuffix nyaeta ahiran file samentawis. File nu ahiranna kieu sésa
+// rewrite nu teu anggeus (crash / disk pinuh) sarta dipiceun ku Recover.
+const TempSuffix = ".maungtmp"
+
+// AtomicFile nulis ka file samentawis; eusina kakara ngaganti file asli
+// sanggeus Commit (fsync -> rename -> fsync diréktori).
+type AtomicFile struct {
+	*os.File
+	path     string
+	prepared bool
+	done     bool
+}
+
+// Create muka file samentawis di diréktori nu sarua jeung path.
+func Create(path string, perm os.FileMode) (*AtomicFile, error) {
+	dir, base := filepath.Split(path)
+	if dir == "" {
+		dir = "."
+	}
+
+	f, err := os.CreateTemp(dir, base+".*"+TempSuffix)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := f.Chmod(perm); err != nil && runtime.GOOS != "windows" {
+		f.Close()
+		os.Remove(f.Name())
+		return nil, err
+	}
+
+	return &AtomicFile{File: f, path: path}, nil
+}
+
+// Prepare nge-fsync & nutup file samentawis tanpa ngaganti file asli.
+// Sanggeus ieu, Name() tiasa dicatet heula (contona dina WAL) saméméh Commit.
+func (a *AtomicFile) Prepare() error {
+	if a.prepared {
+		return nil
+	}
+	a.prepared = true
+
+	if err := a.File.Sync(); err != nil {
+		a.File.Close()
+		return err
+	}
+	return a.File.Close()
+}
+
+// Commit ngaganti file asli ku eusi file samentawis.
+func (a *AtomicFile) Commit() error {
+	if a.done {
+		return nil
+	}
+
+	if err := a.Prepare(); err != nil {
+		a.Abort()
+		return err
+	}
+	a.done = true
+
+	if err := Replace(a.File.Name(), a.path); err != nil {
+		os.Remove(a.File.Name())
+		return err
+	}
+	return nil
+}
+
+// Replace ngaganti dst ku src (nu geus di-fsync) tuluy nge-fsync diréktorina.
+func Replace(src, dst string) error {
+	if err := os.Rename(src, dst); err != nil {
+		return err
+	}
+	return SyncDir(filepath.Dir(dst))
+}
+
+// Abort miceun file samentawis; file asli teu kaganggu.
+func (a *AtomicFile) Abort() {
+	if a.done {
+		return
+	}
+	a.done = true
+
+	if !a.prepared {
+		a.File.Close()
+	}
+	os.Remove(a.File.Name())
+}
+
+// WriteFile sarua jeung os.WriteFile tapi atomic: boh eusi heubeul boh eusi
+// anyar, moal aya file nu kapotong satengah.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	f, err := Create(path, perm)
+	if err != nil {
+		return err
+	}
+
+	if _, err := f.Write(data); err != nil {
+		f.Abort()
+		return err
+	}
+
+	return f.Commit()
+}
+
+// SyncDir nge-fsync diréktori supaya rename-na awét sanggeus listrik pareum.
+func SyncDir(dir string) error {
+	if runtime.GOOS == "windows" {
+		return nil
+	}
+
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+// Recover miceun sésa file samentawis di handapeun root. File asli can
+// kaganti (rename can kajadian), jadi aman dipiceun. File nu skip-na true
+// (contona keur dipaké ku prosés séjén) dilewat.
+func Recover(root string, skip func(path string) bool) (int, error) {
+	removed := 0
+
+	err := filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !strings.HasSuffix(d.Name(), TempSuffix) {
+			return nil
+		}
+		if skip != nil && skip(path) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil {
+			return err
+		}
+		removed++
+		return nil
+	})
+
+	return removed, err
+}